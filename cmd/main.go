@@ -14,9 +14,18 @@ import (
 	"github.com/archnets/telegram-bot/config"
 	"github.com/archnets/telegram-bot/internal/auth"
 	"github.com/archnets/telegram-bot/internal/botapp"
+	"github.com/archnets/telegram-bot/internal/botapp/commands"
+	"github.com/archnets/telegram-bot/internal/broadcast"
 	"github.com/archnets/telegram-bot/internal/core"
 	"github.com/archnets/telegram-bot/internal/db"
 	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/archnets/telegram-bot/internal/monitor"
+	"github.com/archnets/telegram-bot/internal/notify"
+	"github.com/archnets/telegram-bot/internal/reminder"
+	"github.com/archnets/telegram-bot/internal/server"
+	"github.com/archnets/telegram-bot/internal/stars"
+	"github.com/archnets/telegram-bot/internal/usage"
+	"github.com/go-telegram/bot"
 )
 
 func main() {
@@ -65,9 +74,57 @@ func main() {
 	// Create session store
 	sessions := auth.NewSQLiteStore(database)
 
-	// Core services (auth, subscription) - these are legacy placeholders
+	// Backend API client shared by the bot and core services; users' expired
+	// tokens are refreshed by logging them in again
+	authClient := auth.NewClient(cfg.APIBaseURL, botToken)
+	tokens := auth.NewTokenSource(authClient, sessions)
+	apiClient := api.NewClient(cfg.APIBaseURL, 10*time.Second)
+	apiClient.SetTokenSource(tokens)
+	apiClient.SetRetryPolicy(api.RetryPolicy{
+		Attempts:  cfg.APIRetryAttempts,
+		BaseDelay: time.Duration(cfg.APIRetryBaseDelayMS) * time.Millisecond,
+		MaxDelay:  time.Duration(cfg.APIRetryMaxDelayMS) * time.Millisecond,
+	})
+	apiClient.SetBreaker(api.BreakerConfig{
+		Threshold: cfg.APIBreakerThreshold,
+		Cooldown:  time.Duration(cfg.APIBreakerCooldownSec) * time.Second,
+	})
+
+	// Core services (auth, subscription)
 	authSvc := core.NewAuthService(nil)
-	subSvc := core.NewSubscriptionService(nil)
+	subSvc := core.NewSubscriptionService(apiClient)
+
+	// Users' notification settings, checked before bot-initiated messages
+	prefs := notify.NewPreferences(apiClient, sessions)
+
+	// Orders placed in the bot are watched until paid (confirmations start once the bot exists)
+	eventStore := notify.NewSQLiteStore(database)
+	orders := notify.NewOrderWatcher(apiClient, eventStore)
+
+	// Backend admin access for polling and finalizing Stars payments
+	adminSession := api.NewAdminSession(apiClient, cfg.AdminEmail, cfg.AdminPassword)
+
+	// Telegram Stars checkout (payments are finalized on the backend with admin access)
+	starsProvider := stars.NewProvider(stars.NewSQLiteStore(database), apiClient, adminSession, tokens, cfg.StarsRate)
+
+	// Admin broadcasts (delivery worker starts once the bot exists)
+	broadcasts := broadcast.NewService(broadcast.NewSQLiteStore(database), sessions, prefs)
+
+	// Subscription reminders (scheduler starts once the bot exists)
+	reminders := reminder.NewScheduler(reminder.NewSQLiteStore(database), sessions, apiClient, reminder.Config{
+		Interval:        time.Duration(cfg.ReminderIntervalM) * time.Minute,
+		ExpiryDays:      cfg.ReminderExpiryDays,
+		TrafficPercents: cfg.ReminderTrafficPercent,
+	})
+
+	// Traffic usage history for /usage
+	usageSampler := usage.NewSampler(usage.NewSQLiteStore(database), sessions, apiClient, time.Duration(cfg.UsageSampleIntervalM)*time.Minute)
+
+	// Multi-step conversation state
+	conversations := commands.NewConversations(commands.NewConversationSQLiteStore(database), commands.DefaultConversationTimeout)
+
+	// /start payloads saved while first-time users pick a language
+	deepLinks := commands.NewDeepLinks(commands.NewDeepLinkSQLiteStore(database), commands.DefaultDeepLinkTimeout)
 
 	// Dependencies for the bot layer
 	deps := botapp.Dependencies{
@@ -75,10 +132,20 @@ func main() {
 		Subscription:    subSvc,
 		WebAppURL:       cfg.WebAppURL,
 		APIBaseURL:      cfg.APIBaseURL,
+		API:             apiClient,
 		BotToken:        botToken,
 		BotNames:        cfg.BotNames,
 		Sessions:        sessions,
+		Tokens:          tokens,
 		RequiredChannel: cfg.RequiredChannel,
+		Broadcast:       broadcasts,
+		Reminders:       reminders,
+		Conversations:   conversations,
+		DeepLinks:       deepLinks,
+		Preferences:     prefs,
+		Orders:          orders,
+		Usage:           usageSampler,
+		Stars:           starsProvider,
 	}
 
 	// Bot configuration
@@ -93,11 +160,153 @@ func main() {
 		log.Fatalf("failed to create bot: %v", err)
 	}
 
-	logger.Infof("Starting Telegram bot...")
-	b.Start(ctx)
+	go broadcasts.Run(ctx, b)
+
+	// Bot-initiated messages to users and admins
+	notifier := notify.NewNotifier(b, sessions, prefs, authSvc.AdminIDs())
+
+	go orders.Run(ctx, notifier)
+
+	if starsProvider.Enabled() {
+		go starsProvider.Run(ctx, notifier)
+		logger.Infof("Telegram Stars payments enabled (%d XTR per 1.00)", cfg.StarsRate)
+	} else if cfg.StarsRate > 0 {
+		logger.Warnf("STARS_RATE is set but ADMIN_EMAIL/ADMIN_PASSWORD are not; Stars payments disabled")
+	}
+
+	if cfg.ReminderIntervalM > 0 {
+		go reminders.Run(ctx, notifier)
+		logger.Infof("Reminder scheduler started (every %d min)", cfg.ReminderIntervalM)
+	}
+
+	if cfg.UsageSampleIntervalM > 0 {
+		go usageSampler.Run(ctx)
+		logger.Infof("Usage sampler started (every %d min)", cfg.UsageSampleIntervalM)
+	}
+
+	// HTTP server for webhook delivery and backend endpoints
+	srv := server.New(cfg.HTTPListenAddr)
+
+	// Node monitoring (fed by heartbeat events and/or polling)
+	nodeMonitor := monitor.New(monitor.NewSQLiteStore(database), notifier, monitor.Config{
+		OfflineGrace:  time.Duration(cfg.MonitorOfflineGraceS) * time.Second,
+		OnlineGrace:   time.Duration(cfg.MonitorOnlineGraceS) * time.Second,
+		CheckInterval: time.Duration(cfg.MonitorCheckIntervalS) * time.Second,
+	})
+	monitorEnabled := false
+
+	if cfg.WebAppAuthPath != "" {
+		if cfg.WebAppURL == "" {
+			log.Fatalf("WEBAPP_AUTH_PATH is set but WEBAPP_URL is empty")
+		}
+		if cfg.WebAppAuthMaxAgeS <= 0 {
+			log.Fatalf("WEBAPP_AUTH_MAX_AGE_SEC must be positive, got %d", cfg.WebAppAuthMaxAgeS)
+		}
+		srv.Handle(cfg.WebAppAuthPath, botapp.WebAppAuthHandler(authClient, tokens, botapp.WebAppAuthConfig{
+			WebAppURL: cfg.WebAppURL,
+			MaxAge:    time.Duration(cfg.WebAppAuthMaxAgeS) * time.Second,
+		}))
+	}
+
+	if cfg.NotifySecret != "" {
+		events := notify.NewRouter()
+		notify.RegisterOrderHandlers(events, notifier, eventStore)
+		notify.RegisterTicketHandlers(events, notifier, eventStore)
+		monitor.RegisterEventHandlers(events, nodeMonitor)
+		srv.Handle(cfg.NotifyPath, server.RequireSignature(cfg.NotifySecret, events))
+		monitorEnabled = true
+	}
+
+	if cfg.MonitorPollIntervalS > 0 && adminSession.Enabled() {
+		interval := time.Duration(cfg.MonitorPollIntervalS) * time.Second
+		go monitor.NewPoller(apiClient, adminSession, nodeMonitor, interval).Run(ctx)
+		monitorEnabled = true
+	}
+
+	if monitorEnabled {
+		go nodeMonitor.Run(ctx)
+		logger.Infof("Node monitor started")
+	}
+
+	webhookMode := cfg.BotMode == config.BotModeWebhook
+	if webhookMode {
+		if err := setupWebhook(ctx, b, cfg, srv); err != nil {
+			logger.Errorf("Failed to set up webhook: %v", err)
+			return
+		}
+	} else {
+		// Clear any webhook left over from a previous webhook-mode run
+		if err := botapp.DeleteWebhook(ctx, b); err != nil {
+			logger.Warnf("Failed to delete webhook: %v", err)
+		}
+	}
+
+	serverDone := make(chan struct{})
+	if srv.HasHandlers() {
+		go func() {
+			defer close(serverDone)
+			if err := srv.Run(ctx); err != nil {
+				logger.Errorf("HTTP server failed: %v", err)
+				cancel()
+			}
+		}()
+	} else {
+		close(serverDone)
+	}
+
+	logger.Infof("Starting Telegram bot (%s mode)...", cfg.BotMode)
+	if webhookMode {
+		b.StartWebhook(ctx)
+		if cfg.WebhookURL != "" {
+			teardownWebhook(b)
+		}
+	} else {
+		b.Start(ctx)
+	}
+	<-serverDone
 	logger.Infof("Bot stopped")
 }
 
+// setupWebhook registers the webhook with Telegram and mounts the update handler.
+// When WEBHOOK_URL is empty the webhook is not registered, so updates can be
+// POSTed to the listener directly for local testing. WEBHOOK_SECRET is then
+// required: nothing else stops anyone reaching the listener from posting updates.
+func setupWebhook(ctx context.Context, b *bot.Bot, cfg config.Config, srv *server.Server) error {
+	webhookCfg := &botapp.WebhookConfig{
+		URL:         cfg.WebhookURL,
+		Path:        cfg.WebhookPath,
+		SecretToken: cfg.WebhookSecret,
+	}
+
+	if webhookCfg.URL == "" {
+		if webhookCfg.SecretToken == "" {
+			return fmt.Errorf("WEBHOOK_SECRET is required when WEBHOOK_URL is empty")
+		}
+		logger.Warnf("WEBHOOK_URL is empty; webhook not registered with Telegram (local testing mode)")
+	} else {
+		if err := botapp.SetWebhook(ctx, b, webhookCfg); err != nil {
+			return err
+		}
+		logger.Infof("Webhook registered: %s%s", webhookCfg.URL, webhookCfg.Path)
+	}
+
+	srv.Handle(webhookCfg.Path, botapp.WebhookHandler(b, webhookCfg.SecretToken))
+	return nil
+}
+
+// teardownWebhook removes the webhook on shutdown. The main context is already
+// cancelled at this point, so a fresh one is used.
+func teardownWebhook(b *bot.Bot) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := botapp.DeleteWebhook(ctx, b); err != nil {
+		logger.Warnf("Failed to delete webhook: %v", err)
+		return
+	}
+	logger.Infof("Webhook deleted")
+}
+
 func bootstrapBotToken(ctx context.Context, cfg config.Config) (string, error) {
 	client := api.NewClient(cfg.APIBaseURL, 10*time.Second)
 
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/archnets/telegram-bot/config"
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/api/apitest"
+)
+
+func TestBootstrapBotToken(t *testing.T) {
+	srv := apitest.NewServer("123:bot-token")
+	defer srv.Close()
+	srv.SetAdmin("root@example.com", "secret")
+
+	cfg := config.Config{
+		APIBaseURL:    srv.URL,
+		AdminEmail:    "root@example.com",
+		AdminPassword: "secret",
+	}
+	ctx := context.Background()
+
+	t.Run("ok", func(t *testing.T) {
+		token, err := bootstrapBotToken(ctx, cfg)
+		if err != nil {
+			t.Fatalf("bootstrapBotToken: %v", err)
+		}
+		if token != "123:bot-token" {
+			t.Errorf("token = %q, want 123:bot-token", token)
+		}
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		cfg := cfg
+		cfg.AdminPassword = "guess"
+		if _, err := bootstrapBotToken(ctx, cfg); !errors.Is(err, &api.Error{Code: api.UserPasswordError}) {
+			t.Fatalf("err = %v, want code %d", err, api.UserPasswordError)
+		}
+	})
+
+	t.Run("config unavailable", func(t *testing.T) {
+		srv.Fail(api.EndpointAdminAuthMethodConfig,
+			apitest.Fault{Status: http.StatusBadGateway},
+			apitest.Fault{Status: http.StatusBadGateway},
+			apitest.Fault{Status: http.StatusBadGateway},
+		)
+		if _, err := bootstrapBotToken(ctx, cfg); !api.IsUnavailable(err) {
+			t.Fatalf("err = %v, want unavailable", err)
+		}
+	})
+}
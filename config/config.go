@@ -7,6 +7,12 @@ import (
 	"github.com/archnets/telegram-bot/internal/env"
 )
 
+// Bot update delivery modes.
+const (
+	BotModePolling = "polling"
+	BotModeWebhook = "webhook"
+)
+
 type Config struct {
 	BotToken        string
 	BotNames        map[string]string // per-language bot names
@@ -18,6 +24,45 @@ type Config struct {
 	RequiredChannel string // channel users must join, e.g. "@Arch_Net"
 	AdminEmail      string
 	AdminPassword   string
+
+	// Backend API resilience
+	APIRetryAttempts      int // tries per retryable request (reads and opted-in writes), including the first
+	APIRetryBaseDelayMS   int // milliseconds before the first retry, doubled for each next one
+	APIRetryMaxDelayMS    int // upper bound of a single retry delay in milliseconds
+	APIBreakerThreshold   int // consecutive failures that stop backend calls; 0 disables the breaker
+	APIBreakerCooldownSec int // seconds backend calls stay stopped before a trial request
+
+	// Update delivery
+	BotMode        string // BotModePolling or BotModeWebhook
+	WebhookURL     string // public base URL Telegram posts to, e.g. "https://bot.example.com"
+	WebhookPath    string // path the webhook is served on, e.g. "/telegram/webhook"
+	WebhookSecret  string // secret token sent in X-Telegram-Bot-Api-Secret-Token
+	HTTPListenAddr string // listen address for the built-in HTTP server, e.g. ":8081"
+
+	// Mini App token exchange
+	WebAppAuthPath    string // path the Mini App posts initData to; endpoint disabled unless set
+	WebAppAuthMaxAgeS int    // seconds initData stays valid after the Mini App opens
+
+	// Backend event ingestion
+	NotifyPath   string // path the backend posts events to
+	NotifySecret string // shared HMAC secret; endpoint disabled if empty
+
+	// Node monitoring
+	MonitorOfflineGraceS  int // seconds without reports before a node is offline
+	MonitorOnlineGraceS   int // seconds a node must report before it is back online
+	MonitorCheckIntervalS int // seconds between transition checks
+	MonitorPollIntervalS  int // seconds between node status polls; 0 disables polling
+
+	// Subscription reminders
+	ReminderIntervalM      int   // minutes between reminder checks; 0 disables reminders
+	ReminderExpiryDays     []int // days before expiry to remind, e.g. [3, 1]
+	ReminderTrafficPercent []int // traffic usage percentages to remind at, e.g. [80, 95, 100]
+
+	// Traffic usage history
+	UsageSampleIntervalM int // minutes between traffic samples; 0 disables sampling
+
+	// Telegram Stars payments
+	StarsRate int // Stars charged per 1.00 of order currency; 0 disables Stars
 }
 
 func Load() Config {
@@ -32,19 +77,67 @@ func Load() Config {
 		timeoutSec = 5
 	}
 
+	// BOT_MODE: "polling" (default) or "webhook"
+	botMode := strings.ToLower(env.GetString("BOT_MODE", BotModePolling))
+	if botMode != BotModeWebhook {
+		botMode = BotModePolling
+	}
+
 	return Config{
 		BotToken: env.GetString("TELEGRAM_BOT_TOKEN", ""),
 		BotNames: map[string]string{
 			"en": env.GetString("BOT_NAME_EN", "Arch Net"),
 			"fa": env.GetString("BOT_NAME_FA", "آرچ نت"),
 		},
-		APIBaseURL:      env.GetString("API_BASE_URL", ""),
-		WebAppURL:       env.GetString("WEBAPP_URL", ""),
-		DBPath:          env.GetString("DB_PATH", "./data/sessions.db"),
-		BotDebug:        botDebug,
-		BotTimeoutS:     timeoutSec,
-		RequiredChannel: env.GetString("REQUIRED_CHANNEL", ""),
-		AdminEmail:      env.GetString("ADMIN_EMAIL", ""),
-		AdminPassword:   env.GetString("ADMIN_PASSWORD", ""),
+		APIBaseURL:            env.GetString("API_BASE_URL", ""),
+		WebAppURL:             env.GetString("WEBAPP_URL", ""),
+		DBPath:                env.GetString("DB_PATH", "./data/sessions.db"),
+		BotDebug:              botDebug,
+		BotTimeoutS:           timeoutSec,
+		RequiredChannel:       env.GetString("REQUIRED_CHANNEL", ""),
+		AdminEmail:            env.GetString("ADMIN_EMAIL", ""),
+		AdminPassword:         env.GetString("ADMIN_PASSWORD", ""),
+		APIRetryAttempts:      env.GetInt("API_RETRY_ATTEMPTS", 3),
+		APIRetryBaseDelayMS:   env.GetInt("API_RETRY_BASE_DELAY_MS", 200),
+		APIRetryMaxDelayMS:    env.GetInt("API_RETRY_MAX_DELAY_MS", 2000),
+		APIBreakerThreshold:   env.GetInt("API_BREAKER_THRESHOLD", 5),
+		APIBreakerCooldownSec: env.GetInt("API_BREAKER_COOLDOWN_SEC", 30),
+
+		BotMode:        botMode,
+		WebhookURL:     strings.TrimSuffix(env.GetString("WEBHOOK_URL", ""), "/"),
+		WebhookPath:    env.GetString("WEBHOOK_PATH", "/telegram/webhook"),
+		WebhookSecret:  env.GetString("WEBHOOK_SECRET", ""),
+		HTTPListenAddr: env.GetString("HTTP_LISTEN_ADDR", ":8081"),
+		WebAppAuthPath: env.GetString("WEBAPP_AUTH_PATH", ""),
+		NotifyPath:     env.GetString("NOTIFY_PATH", "/backend/events"),
+		NotifySecret:   env.GetString("NOTIFY_SECRET", ""),
+
+		WebAppAuthMaxAgeS: env.GetInt("WEBAPP_AUTH_MAX_AGE_SEC", 3600),
+
+		MonitorOfflineGraceS:  env.GetInt("MONITOR_OFFLINE_GRACE_SEC", 180),
+		MonitorOnlineGraceS:   env.GetInt("MONITOR_ONLINE_GRACE_SEC", 60),
+		MonitorCheckIntervalS: env.GetInt("MONITOR_CHECK_INTERVAL_SEC", 30),
+		MonitorPollIntervalS:  env.GetInt("MONITOR_POLL_INTERVAL_SEC", 0),
+
+		ReminderIntervalM:      env.GetInt("REMINDER_INTERVAL_MIN", 60),
+		ReminderExpiryDays:     parseIntList(env.GetString("REMINDER_EXPIRY_DAYS", "3,1")),
+		ReminderTrafficPercent: parseIntList(env.GetString("REMINDER_TRAFFIC_PERCENTS", "80,95,100")),
+
+		UsageSampleIntervalM: env.GetInt("USAGE_SAMPLE_INTERVAL_MIN", 60),
+
+		StarsRate: env.GetInt("STARS_RATE", 0),
+	}
+}
+
+// parseIntList parses a comma-separated list of positive integers, skipping invalid entries.
+func parseIntList(raw string) []int {
+	var list []int
+	for _, p := range strings.Split(raw, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || n <= 0 {
+			continue
+		}
+		list = append(list, n)
 	}
+	return list
 }
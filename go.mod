@@ -6,6 +6,8 @@ require (
 	github.com/go-telegram/bot v1.17.0
 	github.com/golang-migrate/migrate/v4 v4.19.1
 	github.com/nicksnyder/go-i18n/v2 v2.6.0
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
+	golang.org/x/sync v0.18.0
 	golang.org/x/text v0.31.0
 	modernc.org/sqlite v1.41.0
 )
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// AdminSession keeps an admin token, logging in with admin credentials on demand.
+type AdminSession struct {
+	client   *Client
+	email    string
+	password string
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewAdminSession creates an admin session for the given credentials.
+func NewAdminSession(client *Client, email, password string) *AdminSession {
+	return &AdminSession{
+		client:   client,
+		email:    email,
+		password: password,
+	}
+}
+
+// Enabled reports whether admin credentials are configured.
+func (s *AdminSession) Enabled() bool {
+	return s != nil && s.email != "" && s.password != ""
+}
+
+// Do runs fn with an admin token. If the token is rejected,
+// it logs in again and retries once.
+func (s *AdminSession) Do(ctx context.Context, fn func(token string) error) error {
+	if !s.Enabled() {
+		return errors.New("admin credentials not configured")
+	}
+
+	token, err := s.get(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	err = fn(token)
+	if !IsAuthError(ErrorCode(err)) {
+		return err
+	}
+
+	// Admin token expired, log in again
+	if token, err = s.get(ctx, true); err != nil {
+		return err
+	}
+	return fn(token)
+}
+
+// get returns the cached token, logging in if there is none or refresh is set.
+func (s *AdminSession) get(ctx context.Context, refresh bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && !refresh {
+		return s.token, nil
+	}
+
+	token, err := s.client.Login(ctx, s.email, s.password)
+	if err != nil {
+		return "", fmt.Errorf("admin login: %w", err)
+	}
+	s.token = token
+	return token, nil
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// --- Affiliate Types ---
+
+// AffiliateSummary holds the results of a user's referrals.
+type AffiliateSummary struct {
+	Registers       int64 `json:"registers"`        // Users who signed up with the referral code
+	TotalCommission int64 `json:"total_commission"` // Commission earned so far (cents)
+}
+
+// --- Affiliate API Methods ---
+
+// GetAffiliateSummary fetches how many users the user invited and the commission earned.
+func (c *Client) GetAffiliateSummary(ctx context.Context, token string) (*AffiliateSummary, error) {
+	resp, err := c.Get(ctx, EndpointUserAffiliateCount, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var summary AffiliateSummary
+	if err := json.Unmarshal(resp.Data, &summary); err != nil {
+		return nil, fmt.Errorf("unmarshal affiliate summary: %w", err)
+	}
+	return &summary, nil
+}
+
+// WithdrawCommission moves referral commission (cents) to the user's account balance.
+func (c *Client) WithdrawCommission(ctx context.Context, token string, amount int64) error {
+	_, err := c.Post(ctx, EndpointUserCommissionWithdraw, map[string]int64{"amount": amount}, token)
+	return err
+}
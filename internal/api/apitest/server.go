@@ -0,0 +1,409 @@
+// Package apitest provides an in-process fake ArchNet backend for tests.
+//
+// The fake implements the endpoints the bot needs to sign users in and read
+// their account: admin and Telegram login, user info, language, subscriptions
+// and the admin auth-method config. Errors and latency can be scripted per
+// endpoint to exercise the client's error handling.
+package apitest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/api"
+)
+
+// --- Server Types ---
+
+// Server is a fake backend listening on a local address.
+type Server struct {
+	URL string // Base URL for api.NewClient and auth.NewClient
+
+	srv           *httptest.Server
+	botToken      string
+	adminEmail    string
+	adminPassword string
+
+	mu        sync.Mutex
+	users     map[int64]*User  // By Telegram ID
+	tokens    map[string]int64 // Issued user tokens; 0 marks the admin token
+	nextID    int64
+	nextToken int
+	latency   time.Duration
+	faults    map[string][]Fault
+	requests  map[string]int
+}
+
+// User is an account of the fake backend, linked to a Telegram user.
+type User struct {
+	TelegramID    int64
+	Info          api.UserInfo
+	Subscriptions []api.UserSubscription
+	Login         TelegramLogin // Last Telegram login
+}
+
+// TelegramLogin is the body of a Telegram login as received by the backend.
+type TelegramLogin struct {
+	TelegramID int64  `json:"telegram_id"`
+	Username   string `json:"username"`
+	FirstName  string `json:"first_name"`
+	LastName   string `json:"last_name"`
+	Lang       string `json:"lang"`
+	PhotoURL   string `json:"photo_url"`
+	Invite     string `json:"invite"`
+	Timestamp  int64  `json:"timestamp"`
+	Signature  string `json:"signature"`
+}
+
+// Fault is a scripted failure of one request.
+type Fault struct {
+	Code   int           // API error code in a 200 response, e.g. api.UserDisabled
+	Status int           // HTTP status with a plain-text body, e.g. 502; used when Code is 0
+	Delay  time.Duration // Extra latency before responding
+}
+
+// --- Server Lifecycle ---
+
+// NewServer starts a fake backend whose Telegram login accepts requests
+// signed with botToken, and whose admin auth-method config returns it.
+// Callers should Close the server when done.
+func NewServer(botToken string) *Server {
+	s := &Server{
+		botToken:      botToken,
+		adminEmail:    "admin@example.com",
+		adminPassword: "admin",
+		users:         make(map[int64]*User),
+		tokens:        make(map[string]int64),
+		faults:        make(map[string][]Fault),
+		requests:      make(map[string]int),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+api.EndpointLogin, s.handleLogin)
+	mux.HandleFunc("POST "+api.EndpointLoginTelegram, s.handleTelegramLogin)
+	mux.HandleFunc("GET "+api.EndpointUserInfo, s.withUser(s.handleUserInfo))
+	mux.HandleFunc("PUT "+api.EndpointUserLang, s.withUser(s.handleUserLang))
+	mux.HandleFunc("GET "+api.EndpointUserSubscribe, s.withUser(s.handleUserSubscribe))
+	mux.HandleFunc("GET "+api.EndpointAdminAuthMethodConfig, s.withAdmin(s.handleAuthMethodConfig))
+
+	s.srv = httptest.NewServer(s.intercept(mux))
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// --- Scripting ---
+
+// SetAdmin sets the credentials accepted by the admin login.
+func (s *Server) SetAdmin(email, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.adminEmail, s.adminPassword = email, password
+}
+
+// AddUser creates an account for a Telegram user and returns a token for it.
+func (s *Server) AddUser(u User) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user := u
+	if user.Info.ID == 0 {
+		s.nextID++
+		user.Info.ID = s.nextID
+	}
+	s.users[u.TelegramID] = &user
+	return s.issueToken(u.TelegramID)
+}
+
+// User returns a copy of the account of a Telegram user.
+func (s *Server) User(telegramID int64) (User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[telegramID]
+	if !ok {
+		return User{}, false
+	}
+	return *u, true
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// Fail makes the next requests to path fail with the faults, one per request.
+// path is an endpoint without query, e.g. api.EndpointUserInfo.
+func (s *Server) Fail(path string, faults ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[path] = append(s.faults[path], faults...)
+}
+
+// ExpireTokens invalidates every issued token, as if all sessions expired.
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.tokens)
+}
+
+// Requests returns how many requests were made to path, failed ones included.
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+// --- Middleware ---
+
+// intercept counts requests and applies latency and scripted faults.
+func (s *Server) intercept(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[r.URL.Path]++
+		delay := s.latency
+		var fault *Fault
+		if queued := s.faults[r.URL.Path]; len(queued) > 0 {
+			fault = &queued[0]
+			s.faults[r.URL.Path] = queued[1:]
+			delay += fault.Delay
+		}
+		s.mu.Unlock()
+
+		if delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		switch {
+		case fault == nil:
+			next.ServeHTTP(w, r)
+		case fault.Code != 0:
+			writeError(w, fault.Code, "scripted error")
+		case fault.Status != 0:
+			http.Error(w, http.StatusText(fault.Status), fault.Status)
+		default:
+			next.ServeHTTP(w, r) // Latency only
+		}
+	})
+}
+
+// withUser rejects requests without a valid user token.
+func (s *Server) withUser(next func(http.ResponseWriter, *http.Request, *User)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("Authorization")
+		if token == "" {
+			writeError(w, api.ErrorTokenEmpty, "token is empty")
+			return
+		}
+
+		s.mu.Lock()
+		telegramID, ok := s.tokens[token]
+		user := s.users[telegramID]
+		s.mu.Unlock()
+
+		if !ok || user == nil {
+			writeError(w, api.ErrorTokenExpire, "token expired")
+			return
+		}
+		next(w, r, user)
+	}
+}
+
+// withAdmin rejects requests without the admin token.
+func (s *Server) withAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("Authorization")
+		if token == "" {
+			writeError(w, api.ErrorTokenEmpty, "token is empty")
+			return
+		}
+
+		s.mu.Lock()
+		telegramID, ok := s.tokens[token]
+		s.mu.Unlock()
+
+		switch {
+		case !ok:
+			writeError(w, api.ErrorTokenExpire, "token expired")
+		case telegramID != 0:
+			writeError(w, api.InvalidAccess, "admin access required")
+		default:
+			next(w, r)
+		}
+	}
+}
+
+// --- Handlers ---
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, api.InvalidParams, "invalid body")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.Email != s.adminEmail {
+		writeError(w, api.UserNotExist, "user does not exist")
+		return
+	}
+	if req.Password != s.adminPassword {
+		writeError(w, api.UserPasswordError, "wrong password")
+		return
+	}
+	writeData(w, map[string]string{"token": s.issueToken(0)})
+}
+
+func (s *Server) handleTelegramLogin(w http.ResponseWriter, r *http.Request) {
+	var req TelegramLogin
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TelegramID == 0 {
+		writeError(w, api.InvalidParams, "invalid body")
+		return
+	}
+	if !hmac.Equal([]byte(req.Signature), []byte(s.signature(&req))) {
+		writeError(w, api.InvalidCiphertext, "invalid signature")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[req.TelegramID]
+	if !ok {
+		s.nextID++
+		user = &User{
+			TelegramID: req.TelegramID,
+			Info:       api.UserInfo{ID: s.nextID, Lang: req.Lang},
+		}
+		s.users[req.TelegramID] = user
+	}
+	user.Login = req
+	writeData(w, map[string]string{"token": s.issueToken(req.TelegramID)})
+}
+
+func (s *Server) handleUserInfo(w http.ResponseWriter, _ *http.Request, user *User) {
+	s.mu.Lock()
+	info := user.Info
+	s.mu.Unlock()
+
+	writeData(w, info)
+}
+
+func (s *Server) handleUserLang(w http.ResponseWriter, r *http.Request, user *User) {
+	var req struct {
+		Lang string `json:"lang"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Lang == "" {
+		writeError(w, api.InvalidParams, "invalid body")
+		return
+	}
+
+	s.mu.Lock()
+	user.Info.Lang = req.Lang
+	s.mu.Unlock()
+
+	writeData(w, nil)
+}
+
+func (s *Server) handleUserSubscribe(w http.ResponseWriter, _ *http.Request, user *User) {
+	s.mu.Lock()
+	subs := append([]api.UserSubscription{}, user.Subscriptions...)
+	s.mu.Unlock()
+
+	writeData(w, api.UserSubscriptionsResponse{List: subs, Total: int64(len(subs))})
+}
+
+func (s *Server) handleAuthMethodConfig(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("method") != "telegram" {
+		writeError(w, api.InvalidParams, "unknown auth method")
+		return
+	}
+
+	var result struct {
+		Method string `json:"method"`
+		Config struct {
+			BotToken string `json:"bot_token"`
+		} `json:"config"`
+	}
+	result.Method = "telegram"
+	result.Config.BotToken = s.botToken
+	writeData(w, result)
+}
+
+// --- Private ---
+
+// issueToken creates a token for a Telegram user, or the admin for 0.
+// The caller must hold s.mu.
+func (s *Server) issueToken(telegramID int64) string {
+	s.nextToken++
+	token := fmt.Sprintf("token-%d-%d", telegramID, s.nextToken)
+	s.tokens[token] = telegramID
+	return token
+}
+
+// signature computes the expected signature of a Telegram login the way the
+// backend does: HMAC-SHA256 of the sorted non-empty fields, keyed with
+// SHA256(bot_token).
+func (s *Server) signature(req *TelegramLogin) string {
+	fields := []string{
+		fmt.Sprintf("auth_date=%d", req.Timestamp),
+		fmt.Sprintf("id=%d", req.TelegramID),
+	}
+	if req.FirstName != "" {
+		fields = append(fields, "first_name="+req.FirstName)
+	}
+	if req.LastName != "" {
+		fields = append(fields, "last_name="+req.LastName)
+	}
+	if req.Username != "" {
+		fields = append(fields, "username="+req.Username)
+	}
+	sort.Strings(fields)
+
+	secret := sha256.Sum256([]byte(s.botToken))
+	h := hmac.New(sha256.New, secret[:])
+	h.Write([]byte(strings.Join(fields, "\n")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeData(w http.ResponseWriter, data any) {
+	writeJSON(w, api.Success, "success", data)
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, message, nil)
+}
+
+func writeJSON(w http.ResponseWriter, code int, message string, data any) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(api.Response{Code: code, Message: message, Data: raw})
+}
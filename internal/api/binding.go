@@ -0,0 +1,25 @@
+package api
+
+import "context"
+
+// --- Binding API Methods ---
+
+// SendEmailCode asks the backend to email a verification code of the given type.
+func (c *Client) SendEmailCode(ctx context.Context, token, email string, codeType int) error {
+	req := map[string]any{
+		"email": email,
+		"type":  codeType,
+	}
+	_, err := c.Post(ctx, EndpointSendCode, req, token)
+	return err
+}
+
+// BindEmail binds an email address to the user's account using a verification code.
+func (c *Client) BindEmail(ctx context.Context, token, email, code string) error {
+	req := map[string]string{
+		"email": email,
+		"code":  code,
+	}
+	_, err := c.Put(ctx, EndpointBindEmail, req, token)
+	return err
+}
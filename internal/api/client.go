@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 )
 
@@ -14,6 +17,31 @@ import (
 type Client struct {
 	http    *http.Client
 	baseURL string
+	tokens  TokenSource
+	retry   RetryPolicy
+	breaker *breaker
+}
+
+// TokenSource renews users' tokens for the client. When a request made on
+// behalf of a user (see WithUser) is rejected with an authentication error,
+// the client asks for a fresh token and retries the request once.
+type TokenSource interface {
+	// Refresh returns a valid token for the user in place of stale.
+	// It fails if stale is not a token of the user.
+	Refresh(ctx context.Context, telegramID int64, stale string) (string, error)
+}
+
+type userKey struct{}
+
+// WithUser marks requests made with ctx as made on behalf of the Telegram user.
+func WithUser(ctx context.Context, telegramID int64) context.Context {
+	return context.WithValue(ctx, userKey{}, telegramID)
+}
+
+// userFromContext returns the Telegram user requests are made for, if any.
+func userFromContext(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(userKey{}).(int64)
+	return id, ok
 }
 
 // NewClient creates a new API client.
@@ -24,9 +52,26 @@ func NewClient(baseURL string, timeout time.Duration) *Client {
 	return &Client{
 		http:    &http.Client{Timeout: timeout},
 		baseURL: baseURL,
+		retry:   DefaultRetryPolicy,
+		breaker: newBreaker(DefaultBreakerConfig),
 	}
 }
 
+// SetRetryPolicy sets how retryable requests are retried after transient failures.
+func (c *Client) SetRetryPolicy(p RetryPolicy) {
+	c.retry = p
+}
+
+// SetBreaker replaces the circuit breaker with one using cfg.
+func (c *Client) SetBreaker(cfg BreakerConfig) {
+	c.breaker = newBreaker(cfg)
+}
+
+// SetTokenSource enables token refresh for requests made on behalf of users.
+func (c *Client) SetTokenSource(ts TokenSource) {
+	c.tokens = ts
+}
+
 // Response is the standard API response wrapper.
 type Response struct {
 	Code    int             `json:"code"`
@@ -44,10 +89,83 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("api error %d: %s", e.Code, e.Message)
 }
 
+// Is matches errors with the same code, so a code can be tested with
+// errors.Is(err, &api.Error{Code: api.CouponExpired}).
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// ErrorCode returns the backend code of an API error, or 0 if err is not one.
+func ErrorCode(err error) int {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return 0
+}
+
 // --- Request Helpers ---
 
+// maxResponseSize limits response bodies read from the backend.
+const maxResponseSize = 10 << 20
+
+// maxErrorBody is how much of an unexpected response body is kept in a StatusError.
+const maxErrorBody = 200
+
 // doRequest performs an HTTP request and decodes the response.
+// A user's request rejected for its token is retried once with a refreshed token.
 func (c *Client) doRequest(ctx context.Context, method, path string, body any, auth string) (*Response, error) {
+	resp, err := c.send(ctx, method, path, body, auth)
+	if auth == "" || c.tokens == nil || !IsAuthError(ErrorCode(err)) {
+		return resp, err
+	}
+
+	telegramID, ok := userFromContext(ctx)
+	if !ok {
+		return resp, err
+	}
+	token, refreshErr := c.tokens.Refresh(ctx, telegramID, auth)
+	if refreshErr != nil {
+		return resp, err
+	}
+	return c.send(ctx, method, path, body, token)
+}
+
+// send performs a request, retrying retryable ones after transient failures
+// with jittered exponential backoff.
+func (c *Client) send(ctx context.Context, method, path string, body any, auth string) (*Response, error) {
+	attempts := 1
+	if retryable(ctx, method) {
+		attempts = max(c.retry.Attempts, 1)
+	}
+
+	for n := 1; ; n++ {
+		resp, err := c.attempt(ctx, method, path, body, auth)
+		if err == nil || n >= attempts || !transient(err) {
+			return resp, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return resp, err
+		case <-time.After(c.retry.backoff(n)):
+		}
+	}
+}
+
+// attempt performs a single request unless the circuit breaker is open.
+func (c *Client) attempt(ctx context.Context, method, path string, body any, auth string) (*Response, error) {
+	if !c.breaker.allow(time.Now()) {
+		return nil, ErrUnavailable
+	}
+	resp, err := c.roundTrip(ctx, method, path, body, auth)
+	c.breaker.done(err, time.Now())
+	return resp, err
+}
+
+// roundTrip sends one HTTP request and decodes the response.
+func (c *Client) roundTrip(ctx context.Context, method, path string, body any, auth string) (*Response, error) {
 	var reqBody io.Reader
 	if body != nil {
 		data, err := json.Marshal(body)
@@ -73,9 +191,24 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body any, a
 	}
 	defer resp.Body.Close()
 
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
 	var apiResp Response
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
+	decodeErr := json.Unmarshal(data, &apiResp)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// Error statuses may still carry an API error
+		if decodeErr == nil && apiResp.Code != 0 && !IsSuccess(apiResp.Code) {
+			return &apiResp, &Error{Code: apiResp.Code, Message: apiResp.Message}
+		}
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: truncateBody(data)}
+	}
+
+	if decodeErr != nil {
+		return nil, fmt.Errorf("decode response: %w", decodeErr)
 	}
 
 	if !IsSuccess(apiResp.Code) {
@@ -85,6 +218,15 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body any, a
 	return &apiResp, nil
 }
 
+// truncateBody returns the start of a response body for error messages.
+func truncateBody(data []byte) string {
+	s := strings.TrimSpace(string(data))
+	if len(s) > maxErrorBody {
+		return strings.ToValidUTF8(s[:maxErrorBody], "") + "…"
+	}
+	return s
+}
+
 // Get performs a GET request.
 func (c *Client) Get(ctx context.Context, path, auth string) (*Response, error) {
 	return c.doRequest(ctx, http.MethodGet, path, nil, auth)
@@ -104,11 +246,12 @@ func (c *Client) Put(ctx context.Context, path string, body any, auth string) (*
 
 // UserInfo represents user data from the API.
 type UserInfo struct {
-	ID        int64  `json:"id"`
-	Email     string `json:"email"`
-	Lang      string `json:"lang"`
-	ReferCode string `json:"refer_code"`
-	Balance   int64  `json:"balance"`
+	ID         int64  `json:"id"`
+	Email      string `json:"email"`
+	Lang       string `json:"lang"`
+	ReferCode  string `json:"refer_code"`
+	Balance    int64  `json:"balance"`
+	Commission int64  `json:"commission"` // Referral commission not yet withdrawn (cents)
 }
 
 // --- User API Methods ---
@@ -128,8 +271,9 @@ func (c *Client) GetUserInfo(ctx context.Context, token string) (*UserInfo, erro
 }
 
 // UpdateUserLanguage updates the user's language preference.
+// Setting the same language twice is harmless, so the request is retried.
 func (c *Client) UpdateUserLanguage(ctx context.Context, token, lang string) error {
-	_, err := c.Put(ctx, EndpointUserLang, map[string]string{"lang": lang}, token)
+	_, err := c.Put(WithRetry(ctx), EndpointUserLang, map[string]string{"lang": lang}, token)
 	return err
 }
 
@@ -137,10 +281,22 @@ func (c *Client) UpdateUserLanguage(ctx context.Context, token, lang string) err
 
 // Subscribe represents subscription plan info
 type Subscribe struct {
-	ID          int64  `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Traffic     int64  `json:"traffic"`
+	ID          int64               `json:"id"`
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Traffic     int64               `json:"traffic"`
+	UnitPrice   int64               `json:"unit_price"`  // Minor units (cents) per UnitTime
+	UnitTime    string              `json:"unit_time"`   // Subscribe period unit (Day, Month, Year, ...)
+	Replacement int64               `json:"replacement"` // Traffic reset price (cents)
+	Discount    []SubscribeDiscount `json:"discount"`
+	Inventory   int64               `json:"inventory"` // -1 for unlimited
+	Sell        bool                `json:"sell"`
+}
+
+// SubscribeDiscount is a price discount for buying several periods at once.
+type SubscribeDiscount struct {
+	Quantity int64 `json:"quantity"`
+	Discount int64 `json:"discount"` // Percentage of the regular price
 }
 
 // UserSubscription represents user's active subscription
@@ -148,6 +304,7 @@ type UserSubscription struct {
 	ID          int64     `json:"id"`
 	SubscribeID int64     `json:"subscribe_id"`
 	CustomName  string    `json:"custom_name"`
+	Token       string    `json:"token"` // Subscription link token
 	Traffic     int64     `json:"traffic"`
 	Download    int64     `json:"download"`
 	Upload      int64     `json:"upload"`
@@ -182,7 +339,7 @@ func (c *Client) Login(ctx context.Context, email, password string) (string, err
 		"email":    email,
 		"password": password,
 	}
-	resp, err := c.Post(ctx, "/v1/auth/login", req, "")
+	resp, err := c.Post(ctx, EndpointLogin, req, "")
 	if err != nil {
 		return "", err
 	}
@@ -198,7 +355,7 @@ func (c *Client) Login(ctx context.Context, email, password string) (string, err
 
 // GetAuthMethodConfig fetches authentication method configuration.
 func (c *Client) GetAuthMethodConfig(ctx context.Context, token, method string) (string, error) {
-	resp, err := c.Get(ctx, fmt.Sprintf("/v1/admin/auth-method/config?method=%s", method), token)
+	resp, err := c.Get(ctx, EndpointAdminAuthMethodConfig+"?method="+url.QueryEscape(method), token)
 	if err != nil {
 		return "", err
 	}
@@ -213,3 +370,30 @@ func (c *Client) GetAuthMethodConfig(ctx context.Context, token, method string)
 	}
 	return result.Config.BotToken, nil
 }
+
+// --- Node Types ---
+
+// NodeStatus represents a node's last report to the backend.
+type NodeStatus struct {
+	ID             int64  `json:"id"`
+	Name           string `json:"name"`
+	Country        string `json:"country"`
+	City           string `json:"city"`
+	LastReportedAt int64  `json:"last_reported_at"` // Unix seconds
+}
+
+// GetNodeStatuses fetches the reporting status of all nodes (admin only).
+func (c *Client) GetNodeStatuses(ctx context.Context, token string) ([]NodeStatus, error) {
+	resp, err := c.Get(ctx, EndpointAdminNodeStatus, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		List []NodeStatus `json:"list"`
+	}
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal node status: %w", err)
+	}
+	return result.List, nil
+}
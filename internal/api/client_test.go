@@ -0,0 +1,196 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/api/apitest"
+)
+
+// newClient returns a client for srv that retries without noticeable delay.
+func newClient(srv *apitest.Server) *api.Client {
+	c := api.NewClient(srv.URL, time.Second)
+	c.SetRetryPolicy(api.RetryPolicy{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+	return c
+}
+
+func TestUserEndpoints(t *testing.T) {
+	srv := apitest.NewServer("bot-token")
+	defer srv.Close()
+
+	token := srv.AddUser(apitest.User{
+		TelegramID: 42,
+		Info:       api.UserInfo{Email: "user@example.com", Lang: "en", Balance: 1500},
+		Subscriptions: []api.UserSubscription{
+			{ID: 1, Traffic: 100, Download: 10, Upload: 5},
+		},
+	})
+	client := newClient(srv)
+	ctx := context.Background()
+
+	info, err := client.GetUserInfo(ctx, token)
+	if err != nil {
+		t.Fatalf("GetUserInfo: %v", err)
+	}
+	if info.Email != "user@example.com" || info.Balance != 1500 {
+		t.Errorf("GetUserInfo = %+v", info)
+	}
+
+	if err := client.UpdateUserLanguage(ctx, token, "fa"); err != nil {
+		t.Fatalf("UpdateUserLanguage: %v", err)
+	}
+	if user, _ := srv.User(42); user.Info.Lang != "fa" {
+		t.Errorf("lang = %q, want fa", user.Info.Lang)
+	}
+
+	subs, err := client.GetUserSubscriptions(ctx, token)
+	if err != nil {
+		t.Fatalf("GetUserSubscriptions: %v", err)
+	}
+	if len(subs) != 1 || subs[0].Download != 10 {
+		t.Errorf("GetUserSubscriptions = %+v", subs)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	srv := apitest.NewServer("bot-token")
+	defer srv.Close()
+
+	token := srv.AddUser(apitest.User{TelegramID: 42})
+	srv.Fail(api.EndpointUserInfo, apitest.Fault{Code: api.UserDisabled})
+
+	_, err := newClient(srv).GetUserInfo(context.Background(), token)
+	if !errors.Is(err, &api.Error{Code: api.UserDisabled}) {
+		t.Fatalf("err = %v, want code %d", err, api.UserDisabled)
+	}
+	if n := srv.Requests(api.EndpointUserInfo); n != 1 {
+		t.Errorf("requests = %d, want 1: API errors are not retried", n)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	srv := apitest.NewServer("bot-token")
+	defer srv.Close()
+
+	token := srv.AddUser(apitest.User{TelegramID: 42})
+	client := newClient(srv)
+	ctx := context.Background()
+
+	t.Run("read", func(t *testing.T) {
+		srv.Fail(api.EndpointUserInfo,
+			apitest.Fault{Status: http.StatusBadGateway},
+			apitest.Fault{Status: http.StatusServiceUnavailable},
+		)
+		if _, err := client.GetUserInfo(ctx, token); err != nil {
+			t.Fatalf("GetUserInfo: %v", err)
+		}
+		if n := srv.Requests(api.EndpointUserInfo); n != 3 {
+			t.Errorf("requests = %d, want 3", n)
+		}
+	})
+
+	t.Run("put", func(t *testing.T) {
+		srv.Fail(api.EndpointUserLang, apitest.Fault{Status: http.StatusBadGateway})
+		_, err := client.Put(ctx, api.EndpointUserLang, map[string]string{"lang": "fa"}, token)
+		if !api.IsUnavailable(err) {
+			t.Fatalf("err = %v, want unavailable", err)
+		}
+		if n := srv.Requests(api.EndpointUserLang); n != 1 {
+			t.Errorf("requests = %d, want 1: writes are not retried by default", n)
+		}
+	})
+
+	t.Run("opted in", func(t *testing.T) {
+		srv.Fail(api.EndpointUserLang, apitest.Fault{Status: http.StatusBadGateway})
+		before := srv.Requests(api.EndpointUserLang)
+		if err := client.UpdateUserLanguage(ctx, token, "fa"); err != nil {
+			t.Fatalf("UpdateUserLanguage: %v", err)
+		}
+		if n := srv.Requests(api.EndpointUserLang) - before; n != 2 {
+			t.Errorf("requests = %d, want 2", n)
+		}
+	})
+
+	t.Run("post", func(t *testing.T) {
+		srv.Fail(api.EndpointLogin, apitest.Fault{Status: http.StatusBadGateway})
+		_, err := client.Login(ctx, "admin@example.com", "admin")
+
+		var statusErr *api.StatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadGateway {
+			t.Fatalf("err = %v, want status 502", err)
+		}
+		if !api.IsUnavailable(err) {
+			t.Error("IsUnavailable = false, want true")
+		}
+		if n := srv.Requests(api.EndpointLogin); n != 1 {
+			t.Errorf("requests = %d, want 1", n)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		client := api.NewClient(srv.URL, 50*time.Millisecond)
+		client.SetRetryPolicy(api.RetryPolicy{Attempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+		srv.Fail(api.EndpointUserSubscribe, apitest.Fault{Delay: 200 * time.Millisecond})
+		if _, err := client.GetUserSubscriptions(ctx, token); err != nil {
+			t.Fatalf("GetUserSubscriptions: %v", err)
+		}
+	})
+}
+
+func TestBreaker(t *testing.T) {
+	srv := apitest.NewServer("bot-token")
+	defer srv.Close()
+
+	token := srv.AddUser(apitest.User{TelegramID: 42})
+	client := api.NewClient(srv.URL, time.Second)
+	client.SetRetryPolicy(api.RetryPolicy{Attempts: 1})
+	client.SetBreaker(api.BreakerConfig{Threshold: 2, Cooldown: time.Hour})
+	ctx := context.Background()
+
+	srv.Fail(api.EndpointUserInfo,
+		apitest.Fault{Status: http.StatusServiceUnavailable},
+		apitest.Fault{Status: http.StatusServiceUnavailable},
+	)
+	for range 2 {
+		if _, err := client.GetUserInfo(ctx, token); !api.IsUnavailable(err) {
+			t.Fatalf("err = %v, want unavailable", err)
+		}
+	}
+
+	if _, err := client.GetUserInfo(ctx, token); !errors.Is(err, api.ErrUnavailable) {
+		t.Fatalf("err = %v, want ErrUnavailable", err)
+	}
+	if n := srv.Requests(api.EndpointUserInfo); n != 2 {
+		t.Errorf("requests = %d, want 2: the open circuit must not reach the backend", n)
+	}
+}
+
+func TestAuthMethodConfig(t *testing.T) {
+	srv := apitest.NewServer("bot-token")
+	defer srv.Close()
+
+	client := newClient(srv)
+	ctx := context.Background()
+
+	userToken := srv.AddUser(apitest.User{TelegramID: 42})
+	if _, err := client.GetAuthMethodConfig(ctx, userToken, "telegram"); !errors.Is(err, &api.Error{Code: api.InvalidAccess}) {
+		t.Errorf("user token: err = %v, want code %d", err, api.InvalidAccess)
+	}
+
+	adminToken, err := client.Login(ctx, "admin@example.com", "admin")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	botToken, err := client.GetAuthMethodConfig(ctx, adminToken, "telegram")
+	if err != nil {
+		t.Fatalf("GetAuthMethodConfig: %v", err)
+	}
+	if botToken != "bot-token" {
+		t.Errorf("bot token = %q, want bot-token", botToken)
+	}
+}
@@ -57,6 +57,29 @@ const (
 	OrderStatusFailed   = 4 // Order processing failed
 	OrderStatusFinished = 5 // Order successfully completed (Finished)
 
+	// Order Types
+	OrderTypeSubscribe = 1 // New subscription purchase
+	OrderTypeRenewal   = 2 // Subscription renewal
+	OrderTypeReset     = 3 // Traffic reset
+	OrderTypeRecharge  = 4 // Balance recharge
+
+	// User Subscription Status Codes
+	UserSubscribeStatusPending  = 0 // Subscription created but not yet active
+	UserSubscribeStatusActive   = 1 // Subscription is active
+	UserSubscribeStatusFinished = 2 // Subscription traffic used up
+	UserSubscribeStatusExpired  = 3 // Subscription expired
+	UserSubscribeStatusDeducted = 4 // Subscription deducted (refunded)
+
+	// Verification Code Types
+	VerifyCodeRegister = 1 // Code for registration
+	VerifyCodeSecurity = 2 // Code for security changes (password, email binding)
+
+	// Ticket Status Codes
+	TicketStatusPending  = 1 // Waiting for support
+	TicketStatusWaiting  = 2 // Support replied, waiting for the user
+	TicketStatusResolved = 3 // Resolved by support
+	TicketStatusClosed   = 4 // Closed
+
 	// Coupon Errors
 	CouponNotExist          = 50001
 	CouponAlreadyUsed       = 50002
@@ -101,6 +124,11 @@ func IsAuthError(code int) bool {
 	return code >= 40002 && code <= 40007
 }
 
+// IsCouponError returns true if the code means a coupon can't be applied.
+func IsCouponError(code int) bool {
+	return code >= CouponNotExist && code <= CouponExpired
+}
+
 // IsUserError returns true if the code is a user-related error.
 func IsUserError(code int) bool {
 	return code >= 20001 && code <= 20010
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// --- Device Types ---
+
+// Device represents a client device bound to the user's account.
+type Device struct {
+	ID         int64  `json:"id"`
+	IP         string `json:"ip"`
+	Identifier string `json:"identifier"`
+	UserAgent  string `json:"user_agent"`
+	Online     bool   `json:"online"`
+	Enabled    bool   `json:"enabled"`
+	CreatedAt  int64  `json:"created_at"` // Unix milliseconds
+	UpdatedAt  int64  `json:"updated_at"` // Unix milliseconds, last activity
+}
+
+// --- Device API Methods ---
+
+// GetUserDevices fetches the devices bound to the user's account.
+func (c *Client) GetUserDevices(ctx context.Context, token string) ([]Device, error) {
+	resp, err := c.Get(ctx, EndpointUserDevices, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		List []Device `json:"list"`
+	}
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal devices: %w", err)
+	}
+	return result.List, nil
+}
+
+// UnbindDevice removes a device from the user's account, disconnecting it.
+func (c *Client) UnbindDevice(ctx context.Context, token string, id int64) error {
+	_, err := c.Put(ctx, EndpointUserUnbindDevice, map[string]int64{"id": id}, token)
+	return err
+}
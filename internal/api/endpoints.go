@@ -8,20 +8,31 @@ const (
 	EndpointLoginTelegram = "/v1/auth/login/telegram"
 	EndpointLogout        = "/v1/auth/logout"
 
+	// Common endpoints
+	EndpointSendCode   = "/v1/common/send_code"
+	EndpointSiteConfig = "/v1/common/site/config"
+
 	// User endpoints
-	EndpointUserInfo     = "/v1/public/user/info"
-	EndpointUserLang     = "/v1/public/user/lang"
-	EndpointUserPassword = "/v1/public/user/password"
-	EndpointUserNotify   = "/v1/public/user/notify"
-	EndpointUserDevices  = "/v1/public/user/devices"
+	EndpointUserInfo         = "/v1/public/user/info"
+	EndpointUserLang         = "/v1/public/user/lang"
+	EndpointUserPassword     = "/v1/public/user/password"
+	EndpointUserNotify       = "/v1/public/user/notify"
+	EndpointUserDevices      = "/v1/public/user/devices"
+	EndpointUserUnbindDevice = "/v1/public/user/unbind_device"
+
+	// Affiliate endpoints
+	EndpointUserAffiliateCount     = "/v1/public/user/affiliate/count"
+	EndpointUserCommissionWithdraw = "/v1/public/user/commission_withdraw"
 
 	// Subscription endpoints
-	EndpointUserSubscribe = "/v1/public/user/subscribe"
+	EndpointUserSubscribe      = "/v1/public/user/subscribe"
+	EndpointUserSubscribeToken = "/v1/public/user/subscribe_token"
 
 	// Ticket endpoints
 	EndpointTicket       = "/v1/public/ticket/"
 	EndpointTicketList   = "/v1/public/ticket/list"
 	EndpointTicketDetail = "/v1/public/ticket/detail"
+	EndpointTicketFollow = "/v1/public/ticket/follow"
 
 	// Binding endpoints
 	EndpointBindTelegram = "/v1/public/user/bind_telegram"
@@ -29,6 +40,21 @@ const (
 	EndpointBindMobile   = "/v1/public/user/bind_mobile"
 	EndpointBindOAuth    = "/v1/public/user/bind_oauth"
 
+	// Admin endpoints
+	EndpointAdminAuthMethodConfig = "/v1/admin/auth-method/config"
+	EndpointAdminNodeStatus       = "/v1/admin/server/status"
+	EndpointAdminOrderStatus      = "/v1/admin/order/status"
+
 	// Subscribe catalog endpoints
+	EndpointSubscribeList  = "/v1/public/subscribe/list"
+	EndpointPaymentMethods = "/v1/public/payment/methods"
 
+	// Order endpoints
+	EndpointOrderPre      = "/v1/public/order/pre"
+	EndpointOrderPurchase = "/v1/public/order/purchase"
+	EndpointOrderRenewal  = "/v1/public/order/renewal"
+	EndpointOrderReset    = "/v1/public/order/reset"
+	EndpointOrderRecharge = "/v1/public/order/recharge"
+	EndpointOrderDetail   = "/v1/public/order/detail"
+	EndpointOrderCheckout = "/v1/public/portal/order/checkout"
 )
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// --- Notification Settings Types ---
+
+// NotifySettings holds which bot/email notifications the user wants to receive.
+type NotifySettings struct {
+	OrderUpdates    bool `json:"enable_trade_notify"`
+	ExpiryReminders bool `json:"enable_subscribe_notify"`
+	TrafficAlerts   bool `json:"enable_traffic_notify"`
+	Announcements   bool `json:"enable_announcement_notify"`
+}
+
+// --- Notification Settings API Methods ---
+
+// GetNotifySettings fetches the user's notification preferences.
+// The backend has no read endpoint for them: EndpointUserNotify only accepts
+// updates, and the flags are returned as part of the user info.
+func (c *Client) GetNotifySettings(ctx context.Context, token string) (*NotifySettings, error) {
+	resp, err := c.Get(ctx, EndpointUserInfo, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var settings NotifySettings
+	if err := json.Unmarshal(resp.Data, &settings); err != nil {
+		return nil, fmt.Errorf("unmarshal notify settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// UpdateNotifySettings saves the user's notification preferences.
+// All flags are sent at once, so the request is retried.
+func (c *Client) UpdateNotifySettings(ctx context.Context, token string, settings NotifySettings) error {
+	_, err := c.Put(WithRetry(ctx), EndpointUserNotify, settings, token)
+	return err
+}
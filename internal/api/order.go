@@ -0,0 +1,198 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// Subscribe period units.
+const (
+	UnitTimeDay   = "Day"
+	UnitTimeMonth = "Month"
+	UnitTimeYear  = "Year"
+)
+
+// --- Catalog Types ---
+
+// PaymentMethod is a payment option offered by the backend.
+type PaymentMethod struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Platform   string `json:"platform"` // e.g. "balance", "stripe", "alipay_f2f"
+	FeeMode    int    `json:"fee_mode"`
+	FeePercent int64  `json:"fee_percent"`
+	FeeAmount  int64  `json:"fee_amount"`
+}
+
+// Payment platforms handled by the bot itself.
+const (
+	PaymentPlatformBalance = "balance"        // Paid from the user's account balance
+	PaymentPlatformStars   = "telegram_stars" // Paid with Telegram Stars through an in-chat invoice
+)
+
+// --- Order Types ---
+
+// OrderRequest selects what to buy. Fields unused by an order type are omitted.
+type OrderRequest struct {
+	SubscribeID     int64  `json:"subscribe_id,omitempty"`
+	UserSubscribeID int64  `json:"user_subscribe_id,omitempty"`
+	Quantity        int64  `json:"quantity,omitempty"`
+	Amount          int64  `json:"amount,omitempty"` // Recharge amount (cents)
+	Payment         int64  `json:"payment,omitempty"`
+	Coupon          string `json:"coupon,omitempty"`
+}
+
+// OrderPreview is the price breakdown of an order before it is placed.
+type OrderPreview struct {
+	Price          int64  `json:"price"`
+	Amount         int64  `json:"amount"` // Total to pay (cents)
+	Discount       int64  `json:"discount"`
+	Coupon         string `json:"coupon"`
+	CouponDiscount int64  `json:"coupon_discount"`
+	FeeAmount      int64  `json:"fee_amount"`
+}
+
+// Order is a placed order.
+type Order struct {
+	ID      int64  `json:"id"`
+	OrderNo string `json:"order_no"`
+	Type    int    `json:"type"`
+	Status  int    `json:"status"`
+	Amount  int64  `json:"amount"` // Minor units (cents)
+}
+
+// Checkout is how the user pays for an order.
+type Checkout struct {
+	Type        string `json:"type"`         // e.g. "url", "qr", "balance"
+	CheckoutURL string `json:"checkout_url"` // Empty if no redirect is needed
+}
+
+// OrderStatusUpdate changes the status of an order (admin).
+type OrderStatusUpdate struct {
+	ID      int64  `json:"id"`
+	Status  int    `json:"status"`
+	TradeNo string `json:"trade_no,omitempty"` // Payment reference, e.g. a Telegram charge ID
+}
+
+// --- Catalog API Methods ---
+
+// GetSubscribeList fetches the plans available for purchase.
+func (c *Client) GetSubscribeList(ctx context.Context, token string) ([]Subscribe, error) {
+	resp, err := c.Get(ctx, EndpointSubscribeList, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		List []Subscribe `json:"list"`
+	}
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal subscribe list: %w", err)
+	}
+	return result.List, nil
+}
+
+// GetPaymentMethods fetches the enabled payment methods.
+func (c *Client) GetPaymentMethods(ctx context.Context, token string) ([]PaymentMethod, error) {
+	resp, err := c.Get(ctx, EndpointPaymentMethods, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		List []PaymentMethod `json:"list"`
+	}
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal payment methods: %w", err)
+	}
+	return result.List, nil
+}
+
+// --- Order API Methods ---
+
+// PreviewOrder calculates the price of a subscription purchase.
+func (c *Client) PreviewOrder(ctx context.Context, token string, req OrderRequest) (*OrderPreview, error) {
+	resp, err := c.Post(ctx, EndpointOrderPre, req, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var preview OrderPreview
+	if err := json.Unmarshal(resp.Data, &preview); err != nil {
+		return nil, fmt.Errorf("unmarshal order preview: %w", err)
+	}
+	return &preview, nil
+}
+
+// Purchase places a subscription purchase order and returns its order number.
+func (c *Client) Purchase(ctx context.Context, token string, req OrderRequest) (string, error) {
+	return c.placeOrder(ctx, EndpointOrderPurchase, token, req)
+}
+
+// Renew places a renewal order for a user subscription and returns its order number.
+func (c *Client) Renew(ctx context.Context, token string, req OrderRequest) (string, error) {
+	return c.placeOrder(ctx, EndpointOrderRenewal, token, req)
+}
+
+// ResetTraffic places a traffic reset order for a user subscription and returns its order number.
+func (c *Client) ResetTraffic(ctx context.Context, token string, req OrderRequest) (string, error) {
+	return c.placeOrder(ctx, EndpointOrderReset, token, req)
+}
+
+// Recharge places a balance recharge order and returns its order number.
+func (c *Client) Recharge(ctx context.Context, token string, req OrderRequest) (string, error) {
+	return c.placeOrder(ctx, EndpointOrderRecharge, token, req)
+}
+
+// GetOrder fetches an order by its number.
+func (c *Client) GetOrder(ctx context.Context, token, orderNo string) (*Order, error) {
+	resp, err := c.Get(ctx, EndpointOrderDetail+"?order_no="+url.QueryEscape(orderNo), token)
+	if err != nil {
+		return nil, err
+	}
+
+	var order Order
+	if err := json.Unmarshal(resp.Data, &order); err != nil {
+		return nil, fmt.Errorf("unmarshal order: %w", err)
+	}
+	return &order, nil
+}
+
+// CheckoutOrder starts payment of an order.
+func (c *Client) CheckoutOrder(ctx context.Context, token, orderNo string) (*Checkout, error) {
+	req := map[string]string{"orderNo": orderNo}
+	resp, err := c.Post(ctx, EndpointOrderCheckout, req, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var checkout Checkout
+	if err := json.Unmarshal(resp.Data, &checkout); err != nil {
+		return nil, fmt.Errorf("unmarshal checkout: %w", err)
+	}
+	return &checkout, nil
+}
+
+// UpdateOrderStatus changes the status of an order. Requires an admin token.
+func (c *Client) UpdateOrderStatus(ctx context.Context, token string, req OrderStatusUpdate) error {
+	_, err := c.Put(ctx, EndpointAdminOrderStatus, req, token)
+	return err
+}
+
+// placeOrder posts an order request and returns the created order number.
+func (c *Client) placeOrder(ctx context.Context, path, token string, req OrderRequest) (string, error) {
+	resp, err := c.Post(ctx, path, req, token)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		OrderNo string `json:"order_no"`
+	}
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return "", fmt.Errorf("unmarshal order: %w", err)
+	}
+	return result.OrderNo, nil
+}
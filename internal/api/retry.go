@@ -0,0 +1,191 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/logger"
+)
+
+// --- Resilience Types ---
+
+// ErrUnavailable is returned without contacting the backend while the circuit breaker is open.
+var ErrUnavailable = errors.New("backend temporarily unavailable")
+
+// StatusError is a non-2xx HTTP response without an API error in its body,
+// e.g. a reverse proxy's 502 page.
+type StatusError struct {
+	StatusCode int
+	Body       string // Start of the body, for logs
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("http status %d: %s", e.StatusCode, e.Body)
+}
+
+// IsUnavailable returns true if err means the backend could not be reached or
+// is failing: an open circuit, a network error or timeout, or a 5xx response.
+func IsUnavailable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, ErrUnavailable) {
+		return true
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// RetryPolicy controls how retryable requests are retried after transient
+// failures: GET and HEAD requests, and others made with a WithRetry context.
+type RetryPolicy struct {
+	Attempts  int           // Tries per request, including the first; 1 disables retries
+	BaseDelay time.Duration // Delay before the first retry, doubled for each next one
+	MaxDelay  time.Duration // Upper bound of a single delay
+}
+
+// DefaultRetryPolicy is used by new clients.
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:  3,
+	BaseDelay: 200 * time.Millisecond,
+	MaxDelay:  2 * time.Second,
+}
+
+// BreakerConfig controls the circuit breaker that stops calling a failing backend.
+type BreakerConfig struct {
+	Threshold int           // Consecutive failures that open the circuit; 0 disables the breaker
+	Cooldown  time.Duration // How long the circuit stays open before a trial request
+}
+
+// DefaultBreakerConfig is used by new clients.
+var DefaultBreakerConfig = BreakerConfig{
+	Threshold: 5,
+	Cooldown:  30 * time.Second,
+}
+
+// --- Retry ---
+
+// backoff returns the delay before retry n (1-based): a random duration
+// between half and all of BaseDelay·2^(n-1), capped at MaxDelay.
+func (p RetryPolicy) backoff(n int) time.Duration {
+	d := p.BaseDelay << (n - 1)
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+type retryKey struct{}
+
+// WithRetry marks requests made with ctx as safe to send twice, so they are
+// retried after transient failures whatever their method. Only use it for
+// calls that set a state outright, e.g. a language: many PUT endpoints of the
+// backend are not idempotent (binding an email, resetting a token).
+func WithRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryKey{}, true)
+}
+
+// retryable returns true if a request can safely be sent twice: reads, and
+// requests explicitly opted in with WithRetry.
+func retryable(ctx context.Context, method string) bool {
+	if method == http.MethodGet || method == http.MethodHead {
+		return true
+	}
+	opted, _ := ctx.Value(retryKey{}).(bool)
+	return opted
+}
+
+// transient returns true if a failed request is worth retrying.
+func transient(err error) bool {
+	if errors.Is(err, ErrUnavailable) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// --- Circuit Breaker ---
+
+// breaker opens after Threshold consecutive failed requests, rejecting calls
+// for Cooldown. Then a single trial request decides whether it closes again.
+type breaker struct {
+	cfg BreakerConfig
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool // A trial request is in flight
+}
+
+func newBreaker(cfg BreakerConfig) *breaker {
+	return &breaker{cfg: cfg}
+}
+
+// allow returns true if a request may be sent now.
+func (b *breaker) allow(now time.Time) bool {
+	if b.cfg.Threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.cfg.Threshold {
+		return true
+	}
+	if now.Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// done records the outcome of a request let through by allow.
+func (b *breaker) done(err error, now time.Time) {
+	if b.cfg.Threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if errors.Is(err, context.Canceled) {
+		return // Says nothing about the backend
+	}
+
+	if !IsUnavailable(err) {
+		if b.failures >= b.cfg.Threshold {
+			logger.Infof("API circuit closed: backend is responding again")
+		}
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.cfg.Threshold {
+		if !now.Before(b.openUntil) {
+			logger.Warnf("API circuit open for %s after %d failures: %v", b.cfg.Cooldown, b.failures, err)
+		}
+		b.openUntil = now.Add(b.cfg.Cooldown)
+	}
+}
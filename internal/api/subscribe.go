@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// --- Subscription Link Types ---
+
+// SubscribeConfig is how the backend serves subscription links.
+type SubscribeConfig struct {
+	SubscribePath   string `json:"subscribe_path"`   // e.g. "/api/subscribe"
+	SubscribeDomain string `json:"subscribe_domain"` // One or more domains, one per line; empty for the API domain
+}
+
+// --- Subscription Link API Methods ---
+
+// GetSubscribeConfig fetches the subscription link settings from the public site config.
+func (c *Client) GetSubscribeConfig(ctx context.Context) (*SubscribeConfig, error) {
+	resp, err := c.Get(ctx, EndpointSiteConfig, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Subscribe SubscribeConfig `json:"subscribe"`
+	}
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal site config: %w", err)
+	}
+	return &result.Subscribe, nil
+}
+
+// SubscribeURL returns the import URL of a subscription token,
+// served from the first subscription domain (or the API domain).
+func (c *Client) SubscribeURL(cfg *SubscribeConfig, token string) string {
+	base := strings.TrimSuffix(c.baseURL, "/")
+	if u, err := url.Parse(c.baseURL); err == nil && u.Host != "" {
+		base = u.Scheme + "://" + u.Host
+	}
+
+	if domains := strings.Fields(strings.ReplaceAll(cfg.SubscribeDomain, ",", " ")); len(domains) > 0 {
+		base = strings.TrimSuffix(domains[0], "/")
+		if !strings.Contains(base, "://") {
+			base = "https://" + base
+		}
+	}
+
+	return base + "/" + strings.Trim(cfg.SubscribePath, "/") + "?token=" + url.QueryEscape(token)
+}
+
+// ResetSubscribeToken replaces a subscription's link token; the old link stops working.
+func (c *Client) ResetSubscribeToken(ctx context.Context, token string, userSubscribeID int64) error {
+	_, err := c.Put(ctx, EndpointUserSubscribeToken, map[string]int64{"user_subscribe_id": userSubscribeID}, token)
+	return err
+}
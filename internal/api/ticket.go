@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// --- Ticket Types ---
+
+// TicketFollow is a single message in a ticket thread.
+type TicketFollow struct {
+	ID        int64  `json:"id"`
+	TicketID  int64  `json:"ticket_id"`
+	From      string `json:"from"` // TicketFromUser or TicketFromAdmin
+	Type      int    `json:"type"` // TicketFollowText or TicketFollowImage
+	Content   string `json:"content"`
+	CreatedAt int64  `json:"created_at"` // Unix milliseconds
+}
+
+// Ticket represents a support ticket.
+type Ticket struct {
+	ID          int64          `json:"id"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Status      int            `json:"status"`
+	Follow      []TicketFollow `json:"follow"`
+	CreatedAt   int64          `json:"created_at"` // Unix milliseconds
+	UpdatedAt   int64          `json:"updated_at"` // Unix milliseconds
+}
+
+// TicketListResponse response
+type TicketListResponse struct {
+	List  []Ticket `json:"list"`
+	Total int64    `json:"total"`
+}
+
+// Ticket message senders and types.
+const (
+	TicketFromUser  = "User"
+	TicketFromAdmin = "Admin"
+
+	TicketFollowText  = 1
+	TicketFollowImage = 2
+)
+
+// --- Ticket API Methods ---
+
+// CreateTicket opens a new support ticket.
+func (c *Client) CreateTicket(ctx context.Context, token, title, description string) error {
+	req := map[string]string{
+		"title":       title,
+		"description": description,
+	}
+	_, err := c.Post(ctx, EndpointTicket, req, token)
+	return err
+}
+
+// GetTickets fetches a page of the user's tickets (page starts at 1).
+func (c *Client) GetTickets(ctx context.Context, token string, page, size int) (*TicketListResponse, error) {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("size", strconv.Itoa(size))
+
+	resp, err := c.Get(ctx, EndpointTicketList+"?"+query.Encode(), token)
+	if err != nil {
+		return nil, err
+	}
+
+	var result TicketListResponse
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal tickets: %w", err)
+	}
+	return &result, nil
+}
+
+// GetTicket fetches a ticket with its message thread.
+func (c *Client) GetTicket(ctx context.Context, token string, id int64) (*Ticket, error) {
+	resp, err := c.Get(ctx, EndpointTicketDetail+"?id="+strconv.FormatInt(id, 10), token)
+	if err != nil {
+		return nil, err
+	}
+
+	var ticket Ticket
+	if err := json.Unmarshal(resp.Data, &ticket); err != nil {
+		return nil, fmt.Errorf("unmarshal ticket: %w", err)
+	}
+	return &ticket, nil
+}
+
+// ReplyTicket adds a text message from the user to a ticket thread.
+func (c *Client) ReplyTicket(ctx context.Context, token string, id int64, content string) error {
+	req := map[string]any{
+		"ticket_id": id,
+		"from":      TicketFromUser,
+		"type":      TicketFollowText,
+		"content":   content,
+	}
+	_, err := c.Post(ctx, EndpointTicketFollow, req, token)
+	return err
+}
+
+// CloseTicket closes a ticket.
+func (c *Client) CloseTicket(ctx context.Context, token string, id int64) error {
+	req := map[string]any{
+		"id":     id,
+		"status": TicketStatusClosed,
+	}
+	_, err := c.Put(ctx, EndpointTicket, req, token)
+	return err
+}
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"cmp"
+	"slices"
 	"sync"
 	"time"
 )
@@ -17,10 +19,18 @@ func (s *Session) IsExpired() bool {
 	return time.Now().After(s.ExpiresAt)
 }
 
+// ActiveSession is the valid session of a user, as listed by a SessionStore.
+type ActiveSession struct {
+	TelegramID int64
+	Token      string
+	Lang       string
+}
+
 // SessionStore defines the interface for session storage backends.
 type SessionStore interface {
 	Set(telegramID int64, session *Session)
 	Get(telegramID int64) (*Session, bool)
+	Active() ([]ActiveSession, error)
 	GetToken(telegramID int64) string
 	GetLang(telegramID int64) string
 	SetLang(telegramID int64, lang string)
@@ -60,6 +70,21 @@ func (s *Store) Get(telegramID int64) (*Session, bool) {
 	return session, true
 }
 
+// Active returns the users with a valid session, ordered by Telegram ID.
+func (s *Store) Active() ([]ActiveSession, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var list []ActiveSession
+	for id, session := range s.sessions {
+		if !session.IsExpired() {
+			list = append(list, ActiveSession{TelegramID: id, Token: session.Token, Lang: session.Lang})
+		}
+	}
+	slices.SortFunc(list, func(a, b ActiveSession) int { return cmp.Compare(a.TelegramID, b.TelegramID) })
+	return list, nil
+}
+
 // GetToken returns the token for a user, or empty string if not found/expired.
 func (s *Store) GetToken(telegramID int64) string {
 	session, ok := s.Get(telegramID)
@@ -50,6 +50,29 @@ func (s *SQLiteStore) Get(telegramID int64) (*Session, bool) {
 	return session, true
 }
 
+// Active returns the users with a valid session, ordered by Telegram ID.
+func (s *SQLiteStore) Active() ([]ActiveSession, error) {
+	rows, err := s.db.Query(`
+		SELECT telegram_id, token, lang FROM sessions
+		WHERE expires_at > ?
+		ORDER BY telegram_id
+	`, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []ActiveSession
+	for rows.Next() {
+		var a ActiveSession
+		if err := rows.Scan(&a.TelegramID, &a.Token, &a.Lang); err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
 // GetToken returns the token for a user, or empty string if not found/expired.
 func (s *SQLiteStore) GetToken(telegramID int64) string {
 	session, ok := s.Get(telegramID)
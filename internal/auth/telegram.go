@@ -24,6 +24,7 @@ type TelegramUser struct {
 	LastName     string
 	LanguageCode string
 	PhotoURL     string // Direct URL to user's profile photo (optional)
+	InviteCode   string // Referral code of the inviter; only used when the account is created (optional)
 }
 
 // Client handles authentication with the backend.
@@ -67,11 +68,13 @@ func (c *Client) Authenticate(user TelegramUser) (string, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("auth failed: status %d, body: %s", resp.StatusCode, string(bodyBytes))
+		return "", fmt.Errorf("auth failed: %w", &api.StatusError{StatusCode: resp.StatusCode, Body: string(bodyBytes)})
 	}
 
 	var result struct {
-		Data struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    struct {
 			Token string `json:"token"`
 		} `json:"data"`
 	}
@@ -79,6 +82,10 @@ func (c *Client) Authenticate(user TelegramUser) (string, error) {
 		return "", fmt.Errorf("decode response: %w", err)
 	}
 
+	if result.Code != 0 && !api.IsSuccess(result.Code) {
+		return "", &api.Error{Code: result.Code, Message: result.Message}
+	}
+
 	if result.Data.Token == "" {
 		return "", fmt.Errorf("auth response missing token: %s", string(bodyBytes))
 	}
@@ -95,6 +102,7 @@ type authRequest struct {
 	LastName   string `json:"last_name,omitempty"`
 	Lang       string `json:"lang,omitempty"`
 	PhotoURL   string `json:"photo_url,omitempty"`
+	Invite     string `json:"invite,omitempty"`
 	Timestamp  int64  `json:"timestamp"`
 	Signature  string `json:"signature"`
 }
@@ -107,6 +115,7 @@ func (c *Client) buildAuthRequest(user TelegramUser) *authRequest {
 		LastName:   user.LastName,
 		Lang:       user.LanguageCode,
 		PhotoURL:   user.PhotoURL,
+		Invite:     user.InviteCode,
 		Timestamp:  time.Now().Unix(),
 	}
 	req.Signature = c.computeSignature(req)
@@ -0,0 +1,126 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/api/apitest"
+	"github.com/archnets/telegram-bot/internal/auth"
+)
+
+func TestAuthenticate(t *testing.T) {
+	srv := apitest.NewServer("bot-token")
+	defer srv.Close()
+
+	user := auth.TelegramUser{
+		ID:           42,
+		Username:     "jdoe",
+		FirstName:    "Jane",
+		LastName:     "Doe",
+		LanguageCode: "ru",
+		InviteCode:   "FRIEND",
+	}
+
+	token, err := auth.NewClient(srv.URL, "bot-token").Authenticate(user)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if token == "" {
+		t.Fatal("empty token")
+	}
+
+	account, ok := srv.User(42)
+	if !ok {
+		t.Fatal("account not created")
+	}
+	if account.Login.Invite != "FRIEND" || account.Info.Lang != "ru" {
+		t.Errorf("login = %+v, info = %+v", account.Login, account.Info)
+	}
+}
+
+func TestAuthenticateWrongBotToken(t *testing.T) {
+	srv := apitest.NewServer("bot-token")
+	defer srv.Close()
+
+	_, err := auth.NewClient(srv.URL, "other-token").Authenticate(auth.TelegramUser{ID: 42, FirstName: "Jane"})
+	if !errors.Is(err, &api.Error{Code: api.InvalidCiphertext}) {
+		t.Fatalf("err = %v, want code %d", err, api.InvalidCiphertext)
+	}
+	if _, ok := srv.User(42); ok {
+		t.Error("account created despite the invalid signature")
+	}
+}
+
+func TestTokenRefresh(t *testing.T) {
+	srv := apitest.NewServer("bot-token")
+	defer srv.Close()
+
+	sessions := auth.NewStore()
+	tokens := auth.NewTokenSource(auth.NewClient(srv.URL, "bot-token"), sessions)
+	client := api.NewClient(srv.URL, time.Second)
+	client.SetTokenSource(tokens)
+
+	user := auth.TelegramUser{ID: 42, FirstName: "Jane"}
+	ctx := auth.WithUser(context.Background(), user)
+
+	token, err := tokens.Token(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	sessions.SetLang(user.ID, "zh")
+	srv.ExpireTokens()
+
+	// Concurrent requests with the expired token share one login
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetUserInfo(ctx, token)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("GetUserInfo: %v", err)
+		}
+	}
+	if n := srv.Requests(api.EndpointLoginTelegram); n != 2 {
+		t.Errorf("logins = %d, want 2 (initial and one refresh)", n)
+	}
+	if got := sessions.GetToken(user.ID); got == token || got == "" {
+		t.Errorf("session token = %q, want a new token", got)
+	}
+	if lang := sessions.GetLang(user.ID); lang != "zh" {
+		t.Errorf("lang = %q, want zh to survive the refresh", lang)
+	}
+}
+
+func TestTokenRefreshNeedsUser(t *testing.T) {
+	srv := apitest.NewServer("bot-token")
+	defer srv.Close()
+
+	tokens := auth.NewTokenSource(auth.NewClient(srv.URL, "bot-token"), auth.NewStore())
+	client := api.NewClient(srv.URL, time.Second)
+	client.SetTokenSource(tokens)
+
+	token := srv.AddUser(apitest.User{TelegramID: 42})
+	srv.ExpireTokens()
+
+	// Without WithUser the client cannot know whose token to renew
+	_, err := client.GetUserInfo(context.Background(), token)
+	if !api.IsAuthError(api.ErrorCode(err)) {
+		t.Fatalf("err = %v, want an auth error", err)
+	}
+	if n := srv.Requests(api.EndpointLoginTelegram); n != 0 {
+		t.Errorf("logins = %d, want 0", n)
+	}
+}
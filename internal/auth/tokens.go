@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultSessionTTL is the session lifetime used when a token carries no expiry.
+const DefaultSessionTTL = 7 * 24 * time.Hour
+
+// expiryMargin ends sessions slightly before their token expires.
+const expiryMargin = time.Minute
+
+// ErrUnknownToken is returned by Refresh for a token the user never held.
+var ErrUnknownToken = errors.New("token does not belong to the user")
+
+type userKey struct{}
+
+// WithUser marks API requests made with ctx as made on behalf of user, whose
+// profile is used if they have to be logged in again.
+func WithUser(ctx context.Context, user TelegramUser) context.Context {
+	return context.WithValue(api.WithUser(ctx, user.ID), userKey{}, user)
+}
+
+// userFromContext returns the profile set by WithUser, or a bare profile with the ID.
+func userFromContext(ctx context.Context, telegramID int64) TelegramUser {
+	if user, ok := ctx.Value(userKey{}).(TelegramUser); ok && user.ID == telegramID {
+		return user
+	}
+	return TelegramUser{ID: telegramID}
+}
+
+// TokenSource hands out users' backend tokens from the session store and logs
+// users in again when their token is rejected. Concurrent logins of the same
+// user share a single backend call.
+type TokenSource struct {
+	client   *Client
+	sessions SessionStore
+	photoURL func(ctx context.Context, telegramID int64) string
+
+	group    singleflight.Group
+	mu       sync.Mutex
+	replaced map[int64]string // Previous token of each user, for requests that raced a refresh
+}
+
+// NewTokenSource creates a token source storing sessions in sessions.
+func NewTokenSource(client *Client, sessions SessionStore) *TokenSource {
+	return &TokenSource{
+		client:   client,
+		sessions: sessions,
+		replaced: make(map[int64]string),
+	}
+}
+
+// SetPhotoLookup sets how a user's profile photo URL is found when they log in.
+func (s *TokenSource) SetPhotoLookup(fn func(ctx context.Context, telegramID int64) string) {
+	s.photoURL = fn
+}
+
+// Token returns the user's session token, logging them in if they have none.
+// The profile comes from ctx (see WithUser).
+func (s *TokenSource) Token(ctx context.Context, telegramID int64) (string, error) {
+	if token := s.sessions.GetToken(telegramID); token != "" {
+		return token, nil
+	}
+	return s.Login(ctx, userFromContext(ctx, telegramID))
+}
+
+// Refresh returns a valid token in place of a rejected one. If another
+// request already replaced stale, the current token is returned as is.
+// Implements api.TokenSource.
+func (s *TokenSource) Refresh(ctx context.Context, telegramID int64, stale string) (string, error) {
+	current := s.sessions.GetToken(telegramID)
+	if stale != current {
+		s.mu.Lock()
+		previous := s.replaced[telegramID]
+		s.mu.Unlock()
+
+		if stale != previous {
+			return "", ErrUnknownToken
+		}
+		if current != "" {
+			return current, nil
+		}
+	}
+	return s.Login(ctx, userFromContext(ctx, telegramID))
+}
+
+// Login authenticates the user with the backend and stores the new session,
+// keeping the user's language.
+func (s *TokenSource) Login(ctx context.Context, user TelegramUser) (string, error) {
+	token, err, _ := s.group.Do(strconv.FormatInt(user.ID, 10), func() (any, error) {
+		return s.login(ctx, user)
+	})
+	if err != nil {
+		return "", err
+	}
+	return token.(string), nil
+}
+
+func (s *TokenSource) login(ctx context.Context, user TelegramUser) (string, error) {
+	if user.PhotoURL == "" && s.photoURL != nil {
+		user.PhotoURL = s.photoURL(ctx, user.ID)
+	}
+
+	token, err := s.client.Authenticate(user)
+	if err != nil {
+		return "", err
+	}
+
+	previous, _ := s.sessions.Get(user.ID)
+	session := &Session{
+		Token:     token,
+		ExpiresAt: sessionExpiry(token, time.Now()),
+	}
+	if previous != nil {
+		session.Lang = previous.Lang
+
+		s.mu.Lock()
+		s.replaced[user.ID] = previous.Token
+		s.mu.Unlock()
+	}
+	s.sessions.Set(user.ID, session)
+
+	logger.ForUser(user.ID).Infof("User authenticated (session until %s)", session.ExpiresAt.Format(time.RFC3339))
+	return token, nil
+}
+
+// sessionExpiry returns when a session with the JWT should end: shortly before
+// its "exp" claim, or after DefaultSessionTTL if the token has none.
+func sessionExpiry(token string, now time.Time) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return now.Add(DefaultSessionTTL)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return now.Add(DefaultSessionTTL)
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return now.Add(DefaultSessionTTL)
+	}
+	return time.Unix(claims.Exp, 0).Add(-expiryMargin)
+}
@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Web App initData validation errors.
+var (
+	ErrInitDataInvalid = errors.New("invalid init data")
+	ErrInitDataExpired = errors.New("init data expired")
+)
+
+// webAppKey is the key the Web App secret is derived from the bot token with.
+const webAppKey = "WebAppData"
+
+// webAppClockSkew is how far in the future auth_date may be, to tolerate a
+// bot clock slightly behind Telegram's.
+const webAppClockSkew = time.Minute
+
+// WebAppInitData is the validated launch data of a Telegram Mini App.
+type WebAppInitData struct {
+	User       TelegramUser
+	AuthDate   time.Time
+	QueryID    string // Set when opened from an inline keyboard button (optional)
+	StartParam string // The startapp parameter of the launch link (optional)
+}
+
+// VerifyWebAppInitData validates Mini App initData signed for this bot and
+// not older than maxAge, and returns the user it was issued to. maxAge must be
+// positive: initData never expiring would make a leaked copy valid forever.
+//
+// Secret = HMAC-SHA256(bot_token, "WebAppData"),
+// hash = hex(HMAC-SHA256(data_check_string, secret)), where data_check_string
+// is every other field as "key=value", sorted by key and joined by "\n".
+func (c *Client) VerifyWebAppInitData(initData string, maxAge time.Duration, now time.Time) (*WebAppInitData, error) {
+	if maxAge <= 0 {
+		return nil, fmt.Errorf("init data max age must be positive, got %v", maxAge)
+	}
+
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInitDataInvalid, err)
+	}
+
+	hash := values.Get("hash")
+	if hash == "" {
+		return nil, fmt.Errorf("%w: missing hash", ErrInitDataInvalid)
+	}
+	if !hmac.Equal([]byte(c.webAppHash(values)), []byte(hash)) {
+		return nil, fmt.Errorf("%w: hash mismatch", ErrInitDataInvalid)
+	}
+
+	ts, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid auth_date", ErrInitDataInvalid)
+	}
+	authDate := time.Unix(ts, 0)
+	if authDate.After(now.Add(webAppClockSkew)) {
+		return nil, fmt.Errorf("%w: auth_date in the future", ErrInitDataInvalid)
+	}
+	if now.Sub(authDate) > maxAge {
+		return nil, ErrInitDataExpired
+	}
+
+	var user struct {
+		ID           int64  `json:"id"`
+		Username     string `json:"username"`
+		FirstName    string `json:"first_name"`
+		LastName     string `json:"last_name"`
+		LanguageCode string `json:"language_code"`
+		PhotoURL     string `json:"photo_url"`
+	}
+	if err := json.Unmarshal([]byte(values.Get("user")), &user); err != nil || user.ID == 0 {
+		return nil, fmt.Errorf("%w: missing user", ErrInitDataInvalid)
+	}
+
+	return &WebAppInitData{
+		User: TelegramUser{
+			ID:           user.ID,
+			Username:     user.Username,
+			FirstName:    user.FirstName,
+			LastName:     user.LastName,
+			LanguageCode: user.LanguageCode,
+			PhotoURL:     user.PhotoURL,
+		},
+		AuthDate:   authDate,
+		QueryID:    values.Get("query_id"),
+		StartParam: values.Get("start_param"),
+	}, nil
+}
+
+// webAppHash computes the expected initData hash of the fields besides "hash".
+func (c *Client) webAppHash(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if key != "hash" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	fields := make([]string, len(keys))
+	for i, key := range keys {
+		fields[i] = key + "=" + values.Get(key)
+	}
+	checkString := strings.Join(fields, "\n")
+
+	secret := hmac.New(sha256.New, []byte(webAppKey))
+	secret.Write([]byte(c.botToken))
+
+	h := hmac.New(sha256.New, secret.Sum(nil))
+	h.Write([]byte(checkString))
+	return hex.EncodeToString(h.Sum(nil))
+}
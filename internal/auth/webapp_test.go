@@ -0,0 +1,142 @@
+package auth_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/auth"
+)
+
+// signInitData encodes fields as initData signed for botToken the way Telegram does.
+func signInitData(botToken string, fields map[string]string) url.Values {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, len(keys))
+	values := url.Values{}
+	for i, key := range keys {
+		lines[i] = key + "=" + fields[key]
+		values.Set(key, fields[key])
+	}
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+	h := hmac.New(sha256.New, secret.Sum(nil))
+	h.Write([]byte(strings.Join(lines, "\n")))
+	values.Set("hash", hex.EncodeToString(h.Sum(nil)))
+	return values
+}
+
+func TestVerifyWebAppInitData(t *testing.T) {
+	const botToken = "123:bot-token"
+	now := time.Unix(1_700_000_000, 0)
+	client := auth.NewClient("http://backend.invalid", botToken)
+
+	signed := func(authDate time.Time) url.Values {
+		return signInitData(botToken, map[string]string{
+			"auth_date":   strconv.FormatInt(authDate.Unix(), 10),
+			"query_id":    "AAF",
+			"start_param": "plan_12",
+			"user":        `{"id":42,"first_name":"Jane","username":"jdoe","language_code":"fa"}`,
+		})
+	}
+
+	tests := []struct {
+		name     string
+		initData func() string
+		maxAge   time.Duration
+		fails    bool
+		is       error // Expected sentinel when fails, if any
+	}{
+		{
+			name:     "valid",
+			initData: func() string { return signed(now.Add(-time.Minute)).Encode() },
+			maxAge:   time.Hour,
+		},
+		{
+			name: "tampered user",
+			initData: func() string {
+				v := signed(now.Add(-time.Minute))
+				v.Set("user", `{"id":7,"first_name":"Mallory"}`)
+				return v.Encode()
+			},
+			maxAge: time.Hour,
+			fails:  true,
+			is:     auth.ErrInitDataInvalid,
+		},
+		{
+			name: "other bot",
+			initData: func() string {
+				return signInitData("456:other-token", map[string]string{
+					"auth_date": strconv.FormatInt(now.Unix(), 10),
+					"user":      `{"id":42,"first_name":"Jane"}`,
+				}).Encode()
+			},
+			maxAge: time.Hour,
+			fails:  true,
+			is:     auth.ErrInitDataInvalid,
+		},
+		{
+			name:     "missing hash",
+			initData: func() string { v := signed(now); v.Del("hash"); return v.Encode() },
+			maxAge:   time.Hour,
+			fails:    true,
+			is:       auth.ErrInitDataInvalid,
+		},
+		{
+			name:     "expired",
+			initData: func() string { return signed(now.Add(-2 * time.Hour)).Encode() },
+			maxAge:   time.Hour,
+			fails:    true,
+			is:       auth.ErrInitDataExpired,
+		},
+		{
+			name:     "future auth_date",
+			initData: func() string { return signed(now.Add(time.Hour)).Encode() },
+			maxAge:   time.Hour,
+			fails:    true,
+			is:       auth.ErrInitDataInvalid,
+		},
+		{
+			name:     "no max age",
+			initData: func() string { return signed(now.Add(-time.Minute)).Encode() },
+			maxAge:   0,
+			fails:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := client.VerifyWebAppInitData(tt.initData(), tt.maxAge, now)
+			if tt.fails {
+				if err == nil {
+					t.Fatal("VerifyWebAppInitData succeeded, want an error")
+				}
+				if tt.is != nil && !errors.Is(err, tt.is) {
+					t.Fatalf("err = %v, want %v", err, tt.is)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyWebAppInitData: %v", err)
+			}
+			if data.User.ID != 42 || data.User.Username != "jdoe" || data.User.LanguageCode != "fa" {
+				t.Errorf("user = %+v", data.User)
+			}
+			if data.StartParam != "plan_12" || data.QueryID != "AAF" {
+				t.Errorf("start_param = %q, query_id = %q", data.StartParam, data.QueryID)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package botapp
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/archnets/telegram-bot/internal/api"
@@ -9,8 +10,13 @@ import (
 	"github.com/archnets/telegram-bot/internal/botapp/commands"
 	"github.com/archnets/telegram-bot/internal/botapp/commands/admins"
 	"github.com/archnets/telegram-bot/internal/botapp/commands/users"
+	"github.com/archnets/telegram-bot/internal/broadcast"
 	"github.com/archnets/telegram-bot/internal/core"
 	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/archnets/telegram-bot/internal/notify"
+	"github.com/archnets/telegram-bot/internal/reminder"
+	"github.com/archnets/telegram-bot/internal/stars"
+	"github.com/archnets/telegram-bot/internal/usage"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 )
@@ -27,10 +33,20 @@ type Dependencies struct {
 	Subscription    *core.SubscriptionService
 	WebAppURL       string
 	APIBaseURL      string
+	API             *api.Client
 	BotToken        string
 	BotNames        map[string]string
 	Sessions        auth.SessionStore
+	Tokens          *auth.TokenSource
 	RequiredChannel string
+	Broadcast       *broadcast.Service
+	Reminders       *reminder.Scheduler
+	Conversations   *commands.Conversations
+	DeepLinks       *commands.DeepLinks
+	Preferences     *notify.Preferences
+	Orders          *notify.OrderWatcher
+	Usage           *usage.Sampler
+	Stars           *stars.Provider
 }
 
 // NewBot creates and configures a new Telegram bot instance.
@@ -38,6 +54,13 @@ func NewBot(token string, deps Dependencies, cfg Config) (*bot.Bot, error) {
 	if cfg.InitTimeout == 0 {
 		cfg.InitTimeout = 5 * time.Second
 	}
+	if deps.API == nil {
+		deps.API = api.NewClient(deps.APIBaseURL, 10*time.Second)
+	}
+	if deps.Tokens == nil {
+		deps.Tokens = auth.NewTokenSource(auth.NewClient(deps.APIBaseURL, token), deps.Sessions)
+		deps.API.SetTokenSource(deps.Tokens)
+	}
 
 	// Create shared deps
 	sharedDeps := commands.Deps{
@@ -46,10 +69,18 @@ func NewBot(token string, deps Dependencies, cfg Config) (*bot.Bot, error) {
 		WebAppURL:       deps.WebAppURL,
 		BotToken:        token,
 		BotNames:        deps.BotNames,
-		API:             api.NewClient(deps.APIBaseURL, 10*time.Second),
-		AuthClient:      auth.NewClient(deps.APIBaseURL, token),
+		API:             deps.API,
+		Tokens:          deps.Tokens,
 		Sessions:        deps.Sessions,
 		RequiredChannel: deps.RequiredChannel,
+		Broadcast:       deps.Broadcast,
+		Reminders:       deps.Reminders,
+		Conversations:   deps.Conversations,
+		DeepLinks:       deps.DeepLinks,
+		Preferences:     deps.Preferences,
+		Orders:          deps.Orders,
+		Usage:           deps.Usage,
+		Stars:           deps.Stars,
 	}
 
 	// Configure bot options
@@ -72,11 +103,26 @@ func NewBot(token string, deps Dependencies, cfg Config) (*bot.Bot, error) {
 		return nil, err
 	}
 
+	// Alerts to admins, e.g. about payments needing attention
+	sharedDeps.Notifier = notify.NewNotifier(b, deps.Sessions, deps.Preferences, deps.Auth.AdminIDs())
+
+	// Profile photos are sent to the backend when users log in
+	deps.Tokens.SetPhotoLookup(commands.PhotoLookup(b, token))
+
 	// Setup bot UI elements (WebApp menu button)
 	setupBotUI(context.Background(), b, deps)
 
+	// Bot username for deep links
+	if me, err := b.GetMe(context.Background()); err != nil {
+		logger.Warnf("Failed to get bot username: %v", err)
+	} else {
+		sharedDeps.BotUsername = me.Username
+	}
+
 	registerCommands(b, sharedDeps)
 	registerCallbacks(b, sharedDeps)
+	registerConversations(sharedDeps.Conversations)
+	registerDeepLinks(sharedDeps.DeepLinks)
 
 	return b, nil
 }
@@ -99,16 +145,31 @@ func setupBotUI(ctx context.Context, b *bot.Bot, deps Dependencies) {
 }
 
 func registerCommands(b *bot.Bot, deps commands.Deps) {
-	// /start handles its own auth and channel check (special first-time user flow)
-	register(b, "/start", users.HandleStart, deps)
+	// /start handles its own auth and channel check (special first-time user flow).
+	// It accepts a deep-link payload, e.g. "/start ref_<code>".
+	registerWithArgs(b, "/start", users.HandleStart, deps)
 
 	// Commands with authentication + channel membership middleware
 	register(b, "/status", commands.WithAuthAndChannel(users.HandleStatus), deps)
 	register(b, "/lang", commands.WithAuthAndChannel(users.HandleLanguage), deps)
 	register(b, "/traffic", commands.WithAuthAndChannel(users.HandleTraffic), deps)
+	register(b, "/usage", commands.WithAuthAndChannel(users.HandleUsage), deps)
+	register(b, "/config", commands.WithAuthAndChannel(users.HandleConfig), deps)
+	register(b, "/reminders", commands.WithAuthAndChannel(users.HandleReminders), deps)
+	register(b, "/support", commands.WithAuthAndChannel(users.HandleSupport), deps)
+	register(b, "/devices", commands.WithAuthAndChannel(users.HandleDevices), deps)
+	register(b, "/buy", commands.WithAuthAndChannel(users.HandleBuy), deps)
+	registerWithArgs(b, "/coupon", commands.WithAuthAndChannel(users.HandleCoupon), deps)
+	register(b, "/balance", commands.WithAuthAndChannel(users.HandleBalance), deps)
+	register(b, "/notifications", commands.WithAuthAndChannel(users.HandleNotifications), deps)
+	register(b, "/bindemail", commands.WithAuthAndChannel(users.HandleBindEmail), deps)
+	register(b, "/invite", commands.WithAuthAndChannel(users.HandleInvite), deps)
+	register(b, "/cancel", users.HandleCancel, deps)
 
 	// Admin commands (no channel check for admins)
 	register(b, "/start_admin", admins.HandleStart, deps)
+	registerWithArgs(b, "/broadcast", commands.WithAdmin(admins.HandleBroadcast), deps)
+	registerWithArgs(b, "/refund", commands.WithAdmin(admins.HandleRefund), deps)
 }
 
 func registerCallbacks(b *bot.Bot, deps commands.Deps) {
@@ -119,6 +180,113 @@ func registerCallbacks(b *bot.Bot, deps commands.Deps) {
 		wrapHandler(commands.WithAuth(users.HandleLanguageCallback), deps),
 	)
 
+	b.RegisterHandler(
+		bot.HandlerTypeCallbackQueryData,
+		users.RemindersCallbackPrefix,
+		bot.MatchTypePrefix,
+		wrapHandler(commands.WithAuth(users.HandleRemindersCallback), deps),
+	)
+
+	b.RegisterHandler(
+		bot.HandlerTypeCallbackQueryData,
+		users.SupportCallbackPrefix,
+		bot.MatchTypePrefix,
+		wrapHandler(commands.WithAuth(users.HandleSupportCallback), deps),
+	)
+
+	b.RegisterHandler(
+		bot.HandlerTypeCallbackQueryData,
+		users.DevicesCallbackPrefix,
+		bot.MatchTypePrefix,
+		wrapHandler(commands.WithAuth(users.HandleDevicesCallback), deps),
+	)
+
+	b.RegisterHandler(
+		bot.HandlerTypeCallbackQueryData,
+		users.NotificationsCallbackPrefix,
+		bot.MatchTypePrefix,
+		wrapHandler(commands.WithAuth(users.HandleNotificationsCallback), deps),
+	)
+
+	b.RegisterHandler(
+		bot.HandlerTypeCallbackQueryData,
+		users.BuyCallbackPrefix,
+		bot.MatchTypePrefix,
+		wrapHandler(commands.WithAuth(users.HandleBuyCallback), deps),
+	)
+
+	b.RegisterHandler(
+		bot.HandlerTypeCallbackQueryData,
+		users.SubscriptionCallbackPrefix,
+		bot.MatchTypePrefix,
+		wrapHandler(commands.WithAuth(users.HandleSubscriptionCallback), deps),
+	)
+
+	b.RegisterHandler(
+		bot.HandlerTypeCallbackQueryData,
+		users.BalanceCallbackPrefix,
+		bot.MatchTypePrefix,
+		wrapHandler(commands.WithAuth(users.HandleBalanceCallback), deps),
+	)
+
+	b.RegisterHandler(
+		bot.HandlerTypeCallbackQueryData,
+		users.ReferralCallbackPrefix,
+		bot.MatchTypePrefix,
+		wrapHandler(commands.WithAuth(users.HandleReferralCallback), deps),
+	)
+
+	b.RegisterHandler(
+		bot.HandlerTypeCallbackQueryData,
+		users.ConfigCallbackPrefix,
+		bot.MatchTypePrefix,
+		wrapHandler(commands.WithAuth(users.HandleConfigCallback), deps),
+	)
+
+	b.RegisterHandler(
+		bot.HandlerTypeCallbackQueryData,
+		users.UsageCallbackPrefix,
+		bot.MatchTypePrefix,
+		wrapHandler(commands.WithAuth(users.HandleUsageCallback), deps),
+	)
+
+	// Telegram Stars checkout: pre-checkout queries and the resulting payment messages
+	b.RegisterHandlerMatchFunc(
+		func(u *models.Update) bool { return u.PreCheckoutQuery != nil },
+		wrapHandler(users.HandlePreCheckoutQuery, deps),
+	)
+
+	b.RegisterHandlerMatchFunc(
+		func(u *models.Update) bool { return u.Message != nil && u.Message.SuccessfulPayment != nil },
+		wrapHandler(users.HandleSuccessfulPayment, deps), // The user was charged: never gated on the backend
+	)
+
+	b.RegisterHandler(
+		bot.HandlerTypeCallbackQueryData,
+		admins.BroadcastCallbackPrefix,
+		bot.MatchTypePrefix,
+		wrapHandler(commands.WithAdmin(admins.HandleBroadcastCallback), deps),
+	)
+}
+
+// registerConversations sets the handlers for multi-step flow states.
+func registerConversations(conv *commands.Conversations) {
+	conv.Register(users.StateTicketTitle, users.HandleTicketTitle)
+	conv.Register(users.StateTicketMessage, users.HandleTicketMessage)
+	conv.Register(users.StateTicketReply, users.HandleTicketReply)
+	conv.Register(users.StateBindEmailAddress, users.HandleBindEmailAddress)
+	conv.Register(users.StateBindEmailCode, users.HandleBindEmailCode)
+	conv.Register(users.StateBuy, users.HandleBuyCoupon)
+	conv.Register(users.StateTopUpAmount, users.HandleTopUpAmount)
+	conv.Register(admins.StateBroadcastCompose, admins.HandleBroadcastCompose)
+}
+
+// registerDeepLinks sets the handlers for /start payload prefixes.
+// Referral payloads (ref_<code>) are used when the account is created instead.
+func registerDeepLinks(links *commands.DeepLinks) {
+	links.Register(users.DeepLinkPlan, users.HandlePlanLink)
+	links.Register(users.DeepLinkCoupon, users.HandleCouponLink)
+	links.Register(users.DeepLinkTicket, users.HandleTicketLink)
 }
 
 func register(b *bot.Bot, command string, handler commands.HandlerFunc, deps commands.Deps) {
@@ -130,8 +298,17 @@ func register(b *bot.Bot, command string, handler commands.HandlerFunc, deps com
 	)
 }
 
+// registerWithArgs registers a command that accepts arguments (e.g. "/broadcast text"),
+// both as message text and as photo caption.
+func registerWithArgs(b *bot.Bot, command string, handler commands.HandlerFunc, deps commands.Deps) {
+	name := strings.TrimPrefix(command, "/")
+	for _, handlerType := range []bot.HandlerType{bot.HandlerTypeMessageText, bot.HandlerTypePhotoCaption} {
+		b.RegisterHandler(handlerType, name, bot.MatchTypeCommandStartOnly, wrapHandler(handler, deps))
+	}
+}
+
 func wrapHandler(handler commands.HandlerFunc, deps commands.Deps) bot.HandlerFunc {
 	return func(ctx context.Context, b *bot.Bot, u *models.Update) {
-		handler(ctx, b, u, deps)
+		handler(commands.UserContext(ctx, u), b, u, deps)
 	}
 }
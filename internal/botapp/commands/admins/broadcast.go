@@ -0,0 +1,298 @@
+package admins
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/archnets/telegram-bot/internal/botapp/commands"
+	"github.com/archnets/telegram-bot/internal/broadcast"
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// BroadcastCallbackPrefix is the callback data prefix for broadcast controls.
+const BroadcastCallbackPrefix = "bc:"
+
+// audienceAll is the callback value for "all users".
+const audienceAll = "all"
+
+// broadcastLanguages are the audience filters offered in the preview.
+var broadcastLanguages = []struct {
+	Code  string
+	Label string
+}{
+	{"fa", "🇮🇷 فارسی"},
+	{"en", "🇬🇧 English"},
+	{"ru", "🇷🇺 Русский"},
+	{"zh", "🇨🇳 中文"},
+}
+
+// StateBroadcastCompose is the conversation state waiting for broadcast content.
+const StateBroadcastCompose = "admin.broadcast.compose"
+
+// HandleBroadcast handles the /broadcast command.
+// The content is taken from the command text/photo caption or the replied-to message;
+// with neither, the admin is asked to send the content as the next message.
+// Note: Admin check is handled by middleware.
+func HandleBroadcast(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.Message == nil {
+		return
+	}
+	lang := adminLang(u.Message.From, deps)
+
+	msg := broadcastContent(u.Message)
+	if msg.IsEmpty() {
+		if err := deps.Conversations.Enter(u.Message.From.ID, StateBroadcastCompose, nil); err != nil {
+			logger.ForUpdate(u).Errorf("Enter broadcast compose failed: %v", err)
+		}
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: u.Message.Chat.ID,
+			Text:   i18n.T(i18n.Localizer(lang), "broadcast_compose"),
+		})
+		return
+	}
+
+	draftBroadcast(ctx, b, u, deps, msg, lang)
+}
+
+// HandleBroadcastCompose receives the broadcast content after /broadcast.
+func HandleBroadcastCompose(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps, conv *commands.Conversation) {
+	// State handlers bypass middleware, so check admin access again
+	if !deps.Auth.IsAdmin(u.Message.From.ID) {
+		deps.Conversations.End(u.Message.From.ID)
+		return
+	}
+	lang := adminLang(u.Message.From, deps)
+
+	msg := messageContent(u.Message)
+	if msg.IsEmpty() {
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: u.Message.Chat.ID,
+			Text:   i18n.T(i18n.Localizer(lang), "broadcast_compose"),
+		})
+		return
+	}
+
+	deps.Conversations.End(u.Message.From.ID)
+	draftBroadcast(ctx, b, u, deps, msg, lang)
+}
+
+// draftBroadcast stores a draft and sends its preview with the audience controls.
+func draftBroadcast(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps, msg broadcast.Message, lang string) {
+	lg := logger.ForUpdate(u)
+
+	id, err := deps.Broadcast.CreateDraft(u.Message.From.ID, msg)
+	if err != nil {
+		lg.Errorf("Create broadcast draft failed: %v", err)
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: u.Message.Chat.ID,
+			Text:   i18n.T(i18n.Localizer(lang), "broadcast_error"),
+		})
+		return
+	}
+
+	// Preview exactly what users will receive, then the controls
+	if err := broadcast.Send(ctx, b, u.Message.Chat.ID, msg); err != nil {
+		lg.Warnf("Broadcast preview failed: %v", err)
+	}
+
+	text, keyboard := broadcastControls(id, "", lang, deps)
+	_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      u.Message.Chat.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: keyboard,
+	})
+
+	lg.Infof("Broadcast %d drafted", id)
+}
+
+// HandleBroadcastCallback handles audience selection, confirmation and cancellation.
+// Callback data: "bc:<id>:aud:<lang|all>", "bc:<id>:send", "bc:<id>:cancel".
+// Note: Admin check is handled by middleware.
+func HandleBroadcastCallback(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.CallbackQuery == nil {
+		return
+	}
+	cb := u.CallbackQuery
+	lg := logger.ForUser(cb.From.ID)
+	lang := adminLang(&cb.From, deps)
+	loc := i18n.Localizer(lang)
+
+	parts := strings.Split(strings.TrimPrefix(cb.Data, BroadcastCallbackPrefix), ":")
+	id, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || len(parts) < 2 {
+		answerCallback(ctx, b, cb.ID, "", false)
+		return
+	}
+
+	switch parts[1] {
+	case "aud":
+		audience := ""
+		if len(parts) > 2 && parts[2] != audienceAll {
+			audience = parts[2]
+		}
+		if err := deps.Broadcast.SetAudience(id, audience); err != nil {
+			lg.Errorf("Set broadcast audience failed: %v", err)
+			answerCallback(ctx, b, cb.ID, i18n.T(loc, "broadcast_error"), true)
+			return
+		}
+		text, keyboard := broadcastControls(id, audience, lang, deps)
+		editCallbackMessage(ctx, b, cb, text, keyboard)
+
+	case "send":
+		total, err := deps.Broadcast.Start(id)
+		if err != nil {
+			lg.Warnf("Start broadcast %d failed: %v", id, err)
+			answerCallback(ctx, b, cb.ID, i18n.T(loc, "broadcast_not_draft"), true)
+			return
+		}
+		editCallbackMessage(ctx, b, cb, i18n.TWithData(loc, "broadcast_started", map[string]any{"Total": total}), nil)
+		lg.Infof("Broadcast %d started for %d users", id, total)
+
+	case "cancel":
+		if err := deps.Broadcast.Cancel(id); err != nil {
+			lg.Warnf("Cancel broadcast %d failed: %v", id, err)
+			answerCallback(ctx, b, cb.ID, i18n.T(loc, "broadcast_not_draft"), true)
+			return
+		}
+		editCallbackMessage(ctx, b, cb, i18n.T(loc, "broadcast_cancelled"), nil)
+		lg.Infof("Broadcast %d cancelled", id)
+	}
+
+	answerCallback(ctx, b, cb.ID, "", false)
+}
+
+// broadcastControls builds the preview controls text and keyboard.
+func broadcastControls(id int64, audience, lang string, deps commands.Deps) (string, *models.InlineKeyboardMarkup) {
+	loc := i18n.Localizer(lang)
+
+	count, err := deps.Broadcast.CountAudience(audience)
+	if err != nil {
+		logger.Errorf("Count broadcast audience failed: %v", err)
+	}
+
+	audienceLabel := i18n.T(loc, "broadcast_audience_all")
+	langRow := make([]models.InlineKeyboardButton, 0, len(broadcastLanguages))
+	for _, l := range broadcastLanguages {
+		label := l.Label
+		if l.Code == audience {
+			label = "✅ " + label
+			audienceLabel = l.Label
+		}
+		langRow = append(langRow, models.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("%s%d:aud:%s", BroadcastCallbackPrefix, id, l.Code),
+		})
+	}
+
+	allLabel := i18n.T(loc, "broadcast_audience_all")
+	if audience == "" {
+		allLabel = "✅ " + allLabel
+	}
+
+	text := i18n.TWithData(loc, "broadcast_preview", map[string]any{
+		"Audience": audienceLabel,
+		"Count":    count,
+	})
+
+	keyboard := &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{Text: allLabel, CallbackData: fmt.Sprintf("%s%d:aud:%s", BroadcastCallbackPrefix, id, audienceAll)}},
+			langRow,
+			{
+				{Text: i18n.T(loc, "broadcast_send_button"), CallbackData: fmt.Sprintf("%s%d:send", BroadcastCallbackPrefix, id)},
+				{Text: i18n.T(loc, "broadcast_cancel_button"), CallbackData: fmt.Sprintf("%s%d:cancel", BroadcastCallbackPrefix, id)},
+			},
+		},
+	}
+	return text, keyboard
+}
+
+// broadcastContent extracts the broadcast message from the command message,
+// falling back to the replied-to message when the command has no content.
+func broadcastContent(msg *models.Message) broadcast.Message {
+	content := messageContent(msg)
+	content.Text, content.Entities = stripCommand(content.Text, content.Entities)
+
+	if content.IsEmpty() && msg.ReplyToMessage != nil {
+		return messageContent(msg.ReplyToMessage)
+	}
+	return content
+}
+
+// messageContent returns the text (or caption) and photo of a message.
+func messageContent(msg *models.Message) broadcast.Message {
+	if len(msg.Photo) == 0 {
+		return broadcast.Message{Text: msg.Text, Entities: msg.Entities}
+	}
+	largest := msg.Photo[len(msg.Photo)-1]
+	return broadcast.Message{
+		Text:        msg.Caption,
+		Entities:    msg.CaptionEntities,
+		PhotoFileID: largest.FileID,
+	}
+}
+
+// stripCommand removes a leading bot command and shifts entity offsets accordingly.
+// Entity offsets are in UTF-16 code units.
+func stripCommand(text string, entities []models.MessageEntity) (string, []models.MessageEntity) {
+	if len(entities) == 0 || entities[0].Type != models.MessageEntityTypeBotCommand || entities[0].Offset != 0 {
+		return text, entities
+	}
+
+	runes := []rune(text)
+	cmdLen := entities[0].Length // Commands are ASCII, so UTF-16 units == runes
+	if cmdLen > len(runes) {
+		return text, entities
+	}
+	rest := string(runes[cmdLen:])
+	trimmed := strings.TrimLeft(rest, " \t\n")
+	shift := cmdLen + len(rest) - len(trimmed) // Trimmed characters are ASCII
+
+	shifted := make([]models.MessageEntity, 0, len(entities)-1)
+	for _, e := range entities[1:] {
+		e.Offset -= shift
+		if e.Offset < 0 {
+			continue
+		}
+		shifted = append(shifted, e)
+	}
+	return trimmed, shifted
+}
+
+// adminLang returns the admin's saved language, falling back to Telegram's.
+func adminLang(user *models.User, deps commands.Deps) string {
+	if lang := deps.Sessions.GetLang(user.ID); lang != "" {
+		return lang
+	}
+	return user.LanguageCode
+}
+
+func editCallbackMessage(ctx context.Context, b *bot.Bot, cb *models.CallbackQuery, text string, keyboard *models.InlineKeyboardMarkup) {
+	if cb.Message.Message == nil {
+		return
+	}
+	params := &bot.EditMessageTextParams{
+		ChatID:    cb.Message.Message.Chat.ID,
+		MessageID: cb.Message.Message.ID,
+		Text:      text,
+		ParseMode: models.ParseModeHTML,
+	}
+	if keyboard != nil {
+		params.ReplyMarkup = keyboard
+	}
+	_, _ = b.EditMessageText(ctx, params)
+}
+
+func answerCallback(ctx context.Context, b *bot.Bot, id, text string, alert bool) {
+	_, _ = b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: id,
+		Text:            text,
+		ShowAlert:       alert,
+	})
+}
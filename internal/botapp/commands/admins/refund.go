@@ -0,0 +1,60 @@
+package admins
+
+import (
+	"context"
+	"errors"
+	"html"
+	"strings"
+
+	"github.com/archnets/telegram-bot/internal/botapp/commands"
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/archnets/telegram-bot/internal/stars"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// HandleRefund handles the /refund <charge_id> command, refunding a Telegram Stars payment.
+// Note: Admin check is handled by middleware.
+func HandleRefund(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.Message == nil {
+		return
+	}
+	lg := logger.ForUpdate(u)
+	loc := i18n.Localizer(adminLang(u.Message.From, deps))
+
+	reply := func(text string) {
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    u.Message.Chat.ID,
+			Text:      text,
+			ParseMode: models.ParseModeHTML,
+		})
+	}
+
+	args := strings.Fields(u.Message.Text)
+	if len(args) != 2 {
+		reply(i18n.T(loc, "refund_usage"))
+		return
+	}
+	chargeID := args[1]
+
+	payment, err := deps.Stars.Refund(ctx, b, chargeID)
+	switch {
+	case errors.Is(err, stars.ErrPaymentNotFound):
+		reply(i18n.T(loc, "refund_not_found"))
+	case errors.Is(err, stars.ErrAlreadyRefunded):
+		reply(i18n.T(loc, "refund_already"))
+	case errors.Is(err, stars.ErrOrderFulfilled):
+		reply(i18n.TWithData(loc, "refund_order_fulfilled", map[string]any{"OrderNo": html.EscapeString(payment.OrderNo)}))
+	case err != nil:
+		lg.Errorf("Refund of Stars payment %s failed: %v", chargeID, err)
+		reply(i18n.TWithData(loc, "refund_failed", map[string]any{"Error": html.EscapeString(err.Error())}))
+	default:
+		lg.Infof("Stars payment %s refunded (order %s)", chargeID, payment.OrderNo)
+		reply(i18n.TWithData(loc, "refund_success", map[string]any{
+			"Stars":   payment.Stars,
+			"UserID":  payment.TelegramID,
+			"OrderNo": html.EscapeString(payment.OrderNo),
+		}))
+	}
+}
@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"context"
+	"errors"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Message keys used when an error has no more specific explanation.
+const (
+	apiErrorUnknownKey = "api_error_unknown" // An api.Error with a code missing from apiErrorKeys
+	apiErrorGenericKey = "api_error_generic" // Any other error
+)
+
+// apiErrorKeys maps every backend error code to the message explaining it.
+// Messages may use {{.Code}}.
+var apiErrorKeys = map[int]string{
+	// General
+	api.CodeError:       "api_error_server",
+	api.InvalidParams:   "api_error_invalid_params",
+	api.TooManyRequests: "api_error_too_many_requests",
+
+	// Authentication & Token
+	api.ErrorTokenEmpty:   "session_expired",
+	api.ErrorTokenInvalid: "session_expired",
+	api.ErrorTokenExpire:  "session_expired",
+	api.InvalidAccess:     "session_expired",
+	api.InvalidCiphertext: "session_expired",
+	api.SecretIsEmpty:     "session_expired",
+
+	// User
+	api.UserExist:               "api_error_user_exist",
+	api.UserNotExist:            "api_error_user_not_exist",
+	api.UserPasswordError:       "api_error_user_password",
+	api.UserDisabled:            "api_error_user_disabled",
+	api.InsufficientBalance:     "buy_insufficient_balance",
+	api.StopRegister:            "api_error_stop_register",
+	api.TelegramNotBound:        "api_error_telegram_not_bound",
+	api.UserNotBindOauth:        "api_error_oauth_not_bound",
+	api.InviteCodeError:         "api_error_invite_code",
+	api.UserCommissionNotEnough: "invite_commission_not_enough",
+
+	// Database
+	api.DatabaseQueryError:   "api_error_database",
+	api.DatabaseUpdateError:  "api_error_database",
+	api.DatabaseInsertError:  "api_error_database",
+	api.DatabaseDeletedError: "api_error_database",
+
+	// Subscription
+	api.SubscribeExpired:                "api_error_subscribe_expired",
+	api.SubscribeNotAvailable:           "buy_not_available",
+	api.UserSubscribeExist:              "api_error_subscribe_exist",
+	api.SubscribeIsUsedError:            "api_error_subscribe_in_use",
+	api.SingleSubscribeModeExceedsLimit: "buy_single_mode",
+	api.SubscribeQuotaLimit:             "buy_quota_limit",
+
+	// Order & Payment
+	api.OrderNotExist:         "api_error_order_not_exist",
+	api.PaymentMethodNotFound: "buy_payment_not_found",
+	api.OrderStatusError:      "api_error_order_status",
+	api.InsufficientOfPeriod:  "renew_insufficient_period",
+	api.ExistAvailableTraffic: "reset_traffic_available",
+
+	// Coupon
+	api.CouponNotExist:          "coupon_not_exist",
+	api.CouponAlreadyUsed:       "coupon_already_used",
+	api.CouponNotApplicable:     "coupon_not_applicable",
+	api.CouponInsufficientUsage: "coupon_used_up",
+	api.CouponExpired:           "coupon_expired",
+
+	// Node
+	api.NodeExist:         "api_error_node",
+	api.NodeNotExist:      "api_error_node",
+	api.NodeGroupExist:    "api_error_node",
+	api.NodeGroupNotExist: "api_error_node",
+	api.NodeGroupNotEmpty: "api_error_node",
+
+	// Verification & SMS
+	api.VerifyCodeError:            "bindemail_code_invalid",
+	api.SendSmsError:               "api_error_send_sms",
+	api.SmsNotEnabled:              "api_error_sms_disabled",
+	api.EmailNotEnabled:            "api_error_email_disabled",
+	api.TodaySendCountExceedsLimit: "bindemail_send_limit",
+
+	// Device & Binding
+	api.TelephoneAreaCodeIsEmpty:           "api_error_area_code",
+	api.PasswordIsEmpty:                    "api_error_password_empty",
+	api.AreaCodeIsEmpty:                    "api_error_area_code",
+	api.PasswordOrVerificationCodeRequired: "api_error_password_or_code",
+	api.EmailExist:                         "api_error_email_exist",
+	api.TelephoneExist:                     "api_error_telephone_exist",
+	api.DeviceExist:                        "api_error_device_exist",
+	api.TelephoneError:                     "api_error_telephone",
+	api.DeviceNotExist:                     "api_error_device_not_exist",
+	api.UseridNotMatch:                     "api_error_user_mismatch",
+}
+
+// sentinelError is an error matched with errors.Is and its message.
+type sentinelError struct {
+	err error
+	key string
+}
+
+// sentinelErrorKeys maps errors matched with errors.Is to their message.
+// Packages add their own with RegisterErrorKey.
+var sentinelErrorKeys = []sentinelError{
+	{api.ErrUnavailable, "service_unavailable"},
+}
+
+// RegisterErrorKey sets the message explaining err, and any error wrapping it,
+// in APIErrorMessage. It must be called during initialization, e.g. from an
+// init function, before any handler runs.
+func RegisterErrorKey(err error, key string) {
+	sentinelErrorKeys = append(sentinelErrorKeys, sentinelError{err, key})
+}
+
+// APIErrorMessage returns the message key and template data explaining err
+// to the user. Wrapped errors are unwrapped: sentinel errors first, then an
+// unreachable backend, then the code of an *api.Error.
+func APIErrorMessage(err error) (string, map[string]any) {
+	for _, s := range sentinelErrorKeys {
+		if errors.Is(err, s.err) {
+			return s.key, nil
+		}
+	}
+	if api.IsUnavailable(err) {
+		return "service_unavailable", nil
+	}
+
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) {
+		return apiErrorGenericKey, nil
+	}
+
+	data := map[string]any{"Code": apiErr.Code}
+	if key, ok := apiErrorKeys[apiErr.Code]; ok {
+		return key, data
+	}
+	return apiErrorUnknownKey, data
+}
+
+// SendAPIError sends the localized explanation of err to the chat.
+func SendAPIError(ctx context.Context, b *bot.Bot, chatID int64, lang string, err error) {
+	key, data := APIErrorMessage(err)
+	_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    chatID,
+		Text:      i18n.TWithData(i18n.Localizer(lang), key, data),
+		ParseMode: models.ParseModeHTML,
+	})
+}
@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/archnets/telegram-bot/internal/api"
+)
+
+// backendErrorCodes returns the error codes declared in api/codes.go by name.
+// Status and enum constants (below 100) and Success are not errors.
+func backendErrorCodes(t *testing.T) map[string]int {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), filepath.Join("..", "..", "api", "codes.go"), nil, 0)
+	if err != nil {
+		t.Fatalf("parse codes.go: %v", err)
+	}
+
+	codes := make(map[string]int)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			for i, name := range value.Names {
+				if i >= len(value.Values) {
+					continue
+				}
+				lit, ok := value.Values[i].(*ast.BasicLit)
+				if !ok || lit.Kind != token.INT {
+					continue
+				}
+				code, err := strconv.Atoi(lit.Value)
+				if err != nil || code < 400 {
+					continue
+				}
+				codes[name.Name] = code
+			}
+		}
+	}
+	if len(codes) == 0 {
+		t.Fatal("no error codes found in codes.go")
+	}
+	return codes
+}
+
+// localeKeys returns the message IDs of every locale file by language.
+func localeKeys(t *testing.T) map[string]map[string]struct{} {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join("..", "..", "i18n", "locales", "*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no locale files found: %v", err)
+	}
+
+	locales := make(map[string]map[string]struct{})
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatalf("read %s: %v", f, err)
+		}
+		var messages map[string]json.RawMessage
+		if err := json.Unmarshal(data, &messages); err != nil {
+			t.Fatalf("parse %s: %v", f, err)
+		}
+		keys := make(map[string]struct{}, len(messages))
+		for k := range messages {
+			keys[k] = struct{}{}
+		}
+		locales[filepath.Base(f)] = keys
+	}
+	return locales
+}
+
+func TestEveryErrorCodeIsTranslated(t *testing.T) {
+	codes := backendErrorCodes(t)
+	locales := localeKeys(t)
+
+	required := map[string]string{
+		apiErrorUnknownKey:    "fallback",
+		apiErrorGenericKey:    "fallback",
+		"service_unavailable": "fallback",
+	}
+	for name, code := range codes {
+		key, ok := apiErrorKeys[code]
+		if !ok {
+			t.Errorf("api.%s (%d) has no entry in apiErrorKeys", name, code)
+			continue
+		}
+		required[key] = "api." + name
+	}
+	for _, s := range sentinelErrorKeys {
+		required[s.key] = s.err.Error()
+	}
+
+	for key, source := range required {
+		for locale, keys := range locales {
+			if _, ok := keys[key]; !ok {
+				t.Errorf("%s: missing %q (for %s)", locale, key, source)
+			}
+		}
+	}
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+	errNotPayable := errors.New("order not payable")
+	RegisterErrorKey(errNotPayable, "stars_order_not_payable")
+
+	tests := []struct {
+		name string
+		err  error
+		key  string
+		code any
+	}{
+		{"known code", &api.Error{Code: api.CouponExpired}, "coupon_expired", api.CouponExpired},
+		{"wrapped code", fmt.Errorf("purchase: %w", &api.Error{Code: api.SubscribeQuotaLimit}), "buy_quota_limit", api.SubscribeQuotaLimit},
+		{"unknown code", &api.Error{Code: 99999}, apiErrorUnknownKey, 99999},
+		{"registered", fmt.Errorf("checkout: %w", errNotPayable), "stars_order_not_payable", nil},
+		{"unavailable", &api.StatusError{StatusCode: 502}, "service_unavailable", nil},
+		{"other", fmt.Errorf("boom"), apiErrorGenericKey, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, data := APIErrorMessage(tt.err)
+			if key != tt.key {
+				t.Errorf("key = %q, want %q", key, tt.key)
+			}
+			if got := data["Code"]; got != tt.code {
+				t.Errorf("Code = %v, want %v", got, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// DefaultConversationTimeout is how long a conversation waits for the next message.
+const DefaultConversationTimeout = 10 * time.Minute
+
+// Conversation is a user's active multi-step flow.
+type Conversation struct {
+	State     string
+	Data      map[string]string
+	ExpiresAt time.Time
+}
+
+// IsExpired returns true if the conversation has timed out.
+func (c *Conversation) IsExpired() bool {
+	return time.Now().After(c.ExpiresAt)
+}
+
+// StateHandler handles a message received while the user is in a state.
+type StateHandler func(ctx context.Context, b *bot.Bot, u *models.Update, deps Deps, conv *Conversation)
+
+// Conversations routes free-text messages to the handler of the user's current state.
+type Conversations struct {
+	store    *ConversationSQLiteStore
+	handlers map[string]StateHandler
+	timeout  time.Duration
+}
+
+// NewConversations creates a conversation manager.
+func NewConversations(store *ConversationSQLiteStore, timeout time.Duration) *Conversations {
+	if timeout <= 0 {
+		timeout = DefaultConversationTimeout
+	}
+	return &Conversations{
+		store:    store,
+		handlers: make(map[string]StateHandler),
+		timeout:  timeout,
+	}
+}
+
+// Register sets the handler for a state.
+func (c *Conversations) Register(state string, h StateHandler) {
+	c.handlers[state] = h
+}
+
+// Enter puts the user into a state with the given data, resetting the timeout.
+func (c *Conversations) Enter(userID int64, state string, data map[string]string) error {
+	if data == nil {
+		data = map[string]string{}
+	}
+	return c.store.Set(userID, &Conversation{
+		State:     state,
+		Data:      data,
+		ExpiresAt: time.Now().Add(c.timeout),
+	})
+}
+
+// Get returns the user's active conversation.
+func (c *Conversations) Get(userID int64) (*Conversation, bool) {
+	conv, ok := c.store.Get(userID)
+	if !ok || conv.IsExpired() {
+		return nil, false
+	}
+	return conv, true
+}
+
+// End removes the user's conversation.
+func (c *Conversations) End(userID int64) {
+	c.store.Delete(userID)
+}
+
+// Dispatch routes a message to the handler of the user's current state.
+// Returns false if the user has no active conversation and the message was not handled.
+func (c *Conversations) Dispatch(ctx context.Context, b *bot.Bot, u *models.Update, deps Deps) bool {
+	if u.Message == nil || u.Message.From == nil {
+		return false
+	}
+	user := u.Message.From
+
+	// Commands are never treated as conversation input
+	if strings.HasPrefix(u.Message.Text, "/") {
+		return false
+	}
+
+	conv, ok := c.store.Get(user.ID)
+	if !ok {
+		return false
+	}
+
+	lg := logger.ForUser(user.ID)
+	if conv.IsExpired() {
+		c.store.Delete(user.ID)
+		lg.Debugf("Conversation %s expired", conv.State)
+		sendLocalized(ctx, b, u, deps, "conversation_expired")
+		return true
+	}
+
+	h, ok := c.handlers[conv.State]
+	if !ok {
+		lg.Warnf("No handler for conversation state %s", conv.State)
+		c.store.Delete(user.ID)
+		return false
+	}
+
+	h(ctx, b, u, deps, conv)
+	return true
+}
+
+// sendLocalized sends a translated message in the user's saved language.
+func sendLocalized(ctx context.Context, b *bot.Bot, u *models.Update, deps Deps, key string) {
+	user := getUserFromUpdate(u)
+	chatID := getChatIDFromUpdate(u)
+	if user == nil || chatID == 0 {
+		return
+	}
+
+	lang := deps.Sessions.GetLang(user.ID)
+	if lang == "" {
+		lang = user.LanguageCode
+	}
+
+	_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text:   i18n.T(i18n.Localizer(lang), key),
+	})
+}
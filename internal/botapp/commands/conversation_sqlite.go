@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// ConversationSQLiteStore provides SQLite-backed conversation storage.
+type ConversationSQLiteStore struct {
+	db *sql.DB
+}
+
+// NewConversationSQLiteStore creates a new SQLite conversation store.
+// The db connection should already have migrations applied.
+func NewConversationSQLiteStore(db *sql.DB) *ConversationSQLiteStore {
+	return &ConversationSQLiteStore{db: db}
+}
+
+// Set stores the conversation for a user, replacing any previous one.
+func (s *ConversationSQLiteStore) Set(telegramID int64, conv *Conversation) error {
+	data, err := json.Marshal(conv.Data)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
+		INSERT OR REPLACE INTO conversations (telegram_id, state, data, expires_at)
+		VALUES (?, ?, ?, ?)
+	`, telegramID, conv.State, string(data), conv.ExpiresAt.Unix())
+	return err
+}
+
+// Get retrieves the conversation for a user, including expired ones.
+func (s *ConversationSQLiteStore) Get(telegramID int64) (*Conversation, bool) {
+	var state, data string
+	var expiresAt int64
+
+	err := s.db.QueryRow(`
+		SELECT state, data, expires_at FROM conversations WHERE telegram_id = ?
+	`, telegramID).Scan(&state, &data, &expiresAt)
+	if err != nil {
+		return nil, false
+	}
+
+	conv := &Conversation{
+		State:     state,
+		Data:      map[string]string{},
+		ExpiresAt: time.Unix(expiresAt, 0),
+	}
+	if err := json.Unmarshal([]byte(data), &conv.Data); err != nil {
+		return nil, false
+	}
+	return conv, true
+}
+
+// Delete removes the conversation for a user.
+func (s *ConversationSQLiteStore) Delete(telegramID int64) {
+	_, _ = s.db.Exec(`DELETE FROM conversations WHERE telegram_id = ?`, telegramID)
+}
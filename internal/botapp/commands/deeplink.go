@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// DefaultDeepLinkTimeout is how long a saved /start payload waits for the first-time setup.
+const DefaultDeepLinkTimeout = 24 * time.Hour
+
+// DeepLinkHandler handles a /start payload, given without its prefix (e.g. "12" for "plan_12").
+// It runs once the user is authenticated and has chosen a language; u is either
+// the /start message or the language selection callback.
+type DeepLinkHandler func(ctx context.Context, b *bot.Bot, u *models.Update, deps Deps, arg string)
+
+// deepLinkRoute is a handler with the payload prefix it is registered for.
+type deepLinkRoute struct {
+	prefix  string
+	handler DeepLinkHandler
+}
+
+// DeepLinks routes /start payloads to the handler registered for their prefix.
+type DeepLinks struct {
+	store   *DeepLinkSQLiteStore
+	routes  []deepLinkRoute // Longest prefix first
+	timeout time.Duration
+}
+
+// NewDeepLinks creates a deep-link router.
+func NewDeepLinks(store *DeepLinkSQLiteStore, timeout time.Duration) *DeepLinks {
+	if timeout <= 0 {
+		timeout = DefaultDeepLinkTimeout
+	}
+	return &DeepLinks{
+		store:   store,
+		timeout: timeout,
+	}
+}
+
+// Register sets the handler for payloads starting with prefix, e.g. "plan_".
+// When prefixes overlap, the longest one matching a payload wins.
+func (d *DeepLinks) Register(prefix string, h DeepLinkHandler) {
+	d.routes = slices.DeleteFunc(d.routes, func(r deepLinkRoute) bool { return r.prefix == prefix })
+	i := slices.IndexFunc(d.routes, func(r deepLinkRoute) bool { return len(r.prefix) < len(prefix) })
+	if i < 0 {
+		i = len(d.routes)
+	}
+	d.routes = slices.Insert(d.routes, i, deepLinkRoute{prefix: prefix, handler: h})
+}
+
+// Save keeps a payload until Resume, e.g. while a first-time user picks a language.
+func (d *DeepLinks) Save(userID int64, payload string) error {
+	return d.store.Set(userID, payload, time.Now().Add(d.timeout))
+}
+
+// Resume dispatches the user's saved payload, if any, and forgets it.
+// Returns false if nothing was handled.
+func (d *DeepLinks) Resume(ctx context.Context, b *bot.Bot, u *models.Update, deps Deps) bool {
+	user := getUserFromUpdate(u)
+	if user == nil {
+		return false
+	}
+
+	payload, ok := d.store.Take(user.ID)
+	if !ok {
+		return false
+	}
+	return d.Dispatch(ctx, b, u, deps, payload)
+}
+
+// Dispatch runs the handler registered for the payload's longest matching prefix.
+// Returns false if no handler matches.
+func (d *DeepLinks) Dispatch(ctx context.Context, b *bot.Bot, u *models.Update, deps Deps, payload string) bool {
+	for _, r := range d.routes {
+		if arg, ok := strings.CutPrefix(payload, r.prefix); ok && arg != "" {
+			if user := getUserFromUpdate(u); user != nil {
+				logger.ForUser(user.ID).Debugf("Deep link %s", payload)
+			}
+			r.handler(ctx, b, u, deps, arg)
+			return true
+		}
+	}
+	return false
+}
+
+// StartPayload returns the deep-link payload of a "/start <payload>" command.
+func StartPayload(text string) string {
+	args := strings.Fields(text)
+	if len(args) < 2 {
+		return ""
+	}
+	return args[1]
+}
@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"database/sql"
+	"time"
+)
+
+// DeepLinkSQLiteStore keeps /start payloads that wait for the first-time setup.
+type DeepLinkSQLiteStore struct {
+	db *sql.DB
+}
+
+// NewDeepLinkSQLiteStore creates a new SQLite deep-link store.
+// The db connection should already have migrations applied.
+func NewDeepLinkSQLiteStore(db *sql.DB) *DeepLinkSQLiteStore {
+	return &DeepLinkSQLiteStore{db: db}
+}
+
+// Set stores the pending payload for a user, replacing any previous one.
+func (s *DeepLinkSQLiteStore) Set(telegramID int64, payload string, expiresAt time.Time) error {
+	_, err := s.db.Exec(`
+		INSERT OR REPLACE INTO pending_deeplinks (telegram_id, payload, expires_at)
+		VALUES (?, ?, ?)
+	`, telegramID, payload, expiresAt.Unix())
+	return err
+}
+
+// Take removes and returns the user's pending payload, if it hasn't expired.
+func (s *DeepLinkSQLiteStore) Take(telegramID int64) (string, bool) {
+	var payload string
+	var expiresAt int64
+
+	err := s.db.QueryRow(`
+		DELETE FROM pending_deeplinks WHERE telegram_id = ?
+		RETURNING payload, expires_at
+	`, telegramID).Scan(&payload, &expiresAt)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return "", false
+	}
+	return payload, true
+}
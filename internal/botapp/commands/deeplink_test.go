@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+func TestDeepLinksDispatch(t *testing.T) {
+	var got string
+	handler := func(name string) DeepLinkHandler {
+		return func(_ context.Context, _ *bot.Bot, _ *models.Update, _ Deps, arg string) {
+			got = name + ":" + arg
+		}
+	}
+
+	links := NewDeepLinks(nil, 0)
+	links.Register("c", handler("c"))
+	links.Register("coupon_vip_", handler("vip"))
+	links.Register("coupon_", handler("coupon"))
+	links.Register("plan_", handler("plan"))
+
+	tests := []struct {
+		payload string
+		want    string
+	}{
+		{"plan_12", "plan:12"},
+		{"coupon_SPRING", "coupon:SPRING"},
+		{"coupon_vip_GOLD", "vip:GOLD"},
+		{"cat", "c:at"},
+		{"coupon_", "c:oupon_"},
+		{"ticket_3", ""},
+	}
+	for _, tt := range tests {
+		// Dispatch must be deterministic across runs
+		for range 20 {
+			got = ""
+			handled := links.Dispatch(context.Background(), nil, &models.Update{}, Deps{}, tt.payload)
+			if got != tt.want || handled != (tt.want != "") {
+				t.Fatalf("Dispatch(%q) = %q (handled %v), want %q", tt.payload, got, handled, tt.want)
+			}
+		}
+	}
+}
@@ -4,23 +4,44 @@ package commands
 import (
 	"github.com/archnets/telegram-bot/internal/api"
 	"github.com/archnets/telegram-bot/internal/auth"
+	"github.com/archnets/telegram-bot/internal/broadcast"
 	"github.com/archnets/telegram-bot/internal/core"
+	"github.com/archnets/telegram-bot/internal/notify"
+	"github.com/archnets/telegram-bot/internal/reminder"
+	"github.com/archnets/telegram-bot/internal/stars"
+	"github.com/archnets/telegram-bot/internal/usage"
 )
 
 // Deps contains shared dependencies for all command handlers.
 type Deps struct {
-	// Legacy services
+	// Core services
 	Auth         *core.AuthService
 	Subscription *core.SubscriptionService
 
 	// Config
-	WebAppURL string
-	BotToken  string
-	BotNames  map[string]string
+	WebAppURL   string
+	BotToken    string
+	BotNames    map[string]string
+	BotUsername string // For deep links, e.g. t.me/<username>?start=...
 
 	// API and auth
 	API             *api.Client
-	AuthClient      *auth.Client
+	Tokens          *auth.TokenSource // Users' tokens, logging them in as needed
 	Sessions        auth.SessionStore
 	RequiredChannel string // Channel username users must join (e.g., "@Arch_Net")
+
+	// Multi-step flows
+	Conversations *Conversations
+	DeepLinks     *DeepLinks
+
+	// Background services
+	Broadcast   *broadcast.Service
+	Reminders   *reminder.Scheduler
+	Notifier    *notify.Notifier // Messages to users and admins outside their own updates
+	Preferences *notify.Preferences
+	Orders      *notify.OrderWatcher
+	Usage       *usage.Sampler
+
+	// Payments
+	Stars *stars.Provider
 }
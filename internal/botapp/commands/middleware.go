@@ -3,8 +3,8 @@ package commands
 
 import (
 	"context"
-	"time"
 
+	"github.com/archnets/telegram-bot/internal/api"
 	"github.com/archnets/telegram-bot/internal/auth"
 	"github.com/archnets/telegram-bot/internal/i18n"
 	"github.com/archnets/telegram-bot/internal/logger"
@@ -28,38 +28,17 @@ func WithAuth(next HandlerFunc) HandlerFunc {
 			return
 		}
 
-		// Skip if already authenticated
-		if deps.Sessions.GetToken(user.ID) != "" {
-			next(ctx, b, u, deps)
-			return
-		}
-
-		// Fetch user's profile photo URL
-		lg := logger.ForUser(user.ID)
-		photoURL := getUserPhotoURL(ctx, b, user.ID, deps.BotToken, lg)
-
-		// Authenticate with backend
-		token, err := deps.AuthClient.Authenticate(auth.TelegramUser{
-			ID:           user.ID,
-			Username:     user.Username,
-			FirstName:    user.FirstName,
-			LastName:     user.LastName,
-			LanguageCode: user.LanguageCode,
-			PhotoURL:     photoURL,
-		})
-		if err != nil {
-			lg.Errorf("Auth failed: %v", err)
+		// Logs in only if the user has no session yet
+		if _, err := deps.Tokens.Token(ctx, user.ID); err != nil {
+			logger.ForUser(user.ID).Errorf("Auth failed: %v", err)
+			if api.IsUnavailable(err) {
+				sendServiceUnavailable(ctx, b, u, deps)
+				return
+			}
 			sendAuthError(ctx, b, u, user.LanguageCode)
 			return
 		}
 
-		// Store session
-		deps.Sessions.Set(user.ID, &auth.Session{
-			Token:     token,
-			ExpiresAt: time.Now().Add(7 * 24 * time.Hour),
-		})
-
-		lg.Infof("User authenticated")
 		next(ctx, b, u, deps)
 	}
 }
@@ -80,6 +59,74 @@ func WithAuthAndChannel(next HandlerFunc) HandlerFunc {
 	return WithAuth(WithChannelMembership(next))
 }
 
+// WithAdmin ensures the user is an admin before the handler runs.
+// Non-admins receive an access denied message (or alert for callbacks).
+func WithAdmin(next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, u *models.Update, deps Deps) {
+		user := getUserFromUpdate(u)
+		if user == nil {
+			return
+		}
+
+		if deps.Auth.IsAdmin(user.ID) {
+			next(ctx, b, u, deps)
+			return
+		}
+
+		lang := deps.Sessions.GetLang(user.ID)
+		if lang == "" {
+			lang = user.LanguageCode
+		}
+		text := i18n.T(i18n.Localizer(lang), "access_denied")
+
+		if u.CallbackQuery != nil {
+			_, _ = b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+				CallbackQueryID: u.CallbackQuery.ID,
+				Text:            text,
+				ShowAlert:       true,
+			})
+			return
+		}
+
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: getChatIDFromUpdate(u),
+			Text:   text,
+		})
+	}
+}
+
+// UserContext marks API requests made while handling u as made on behalf of
+// its sender, so expired tokens are refreshed with the sender's profile.
+func UserContext(ctx context.Context, u *models.Update) context.Context {
+	user := getUserFromUpdate(u)
+	if user == nil && u.PreCheckoutQuery != nil {
+		user = u.PreCheckoutQuery.From
+	}
+	if user == nil {
+		return ctx
+	}
+	return auth.WithUser(ctx, TelegramUser(user, ""))
+}
+
+// TelegramUser converts a Telegram user to the profile sent to the backend on login.
+func TelegramUser(user *models.User, inviteCode string) auth.TelegramUser {
+	return auth.TelegramUser{
+		ID:           user.ID,
+		Username:     user.Username,
+		FirstName:    user.FirstName,
+		LastName:     user.LastName,
+		LanguageCode: user.LanguageCode,
+		InviteCode:   inviteCode,
+	}
+}
+
+// PhotoLookup returns a function finding users' profile photo URLs, for auth.TokenSource.
+func PhotoLookup(b *bot.Bot, botToken string) func(ctx context.Context, telegramID int64) string {
+	return func(ctx context.Context, telegramID int64) string {
+		return getUserPhotoURL(ctx, b, telegramID, botToken, logger.ForUser(telegramID))
+	}
+}
+
 // --- Helpers ---
 
 // getUserFromUpdate extracts the user from any update type.
@@ -118,6 +165,30 @@ func sendAuthError(ctx context.Context, b *bot.Bot, u *models.Update, lang strin
 	})
 }
 
+// sendServiceUnavailable tells the user the backend is down (an alert for callbacks).
+func sendServiceUnavailable(ctx context.Context, b *bot.Bot, u *models.Update, deps Deps) {
+	user := getUserFromUpdate(u)
+	lang := deps.Sessions.GetLang(user.ID)
+	if lang == "" {
+		lang = user.LanguageCode
+	}
+	text := i18n.T(i18n.Localizer(lang), "service_unavailable")
+
+	if u.CallbackQuery != nil {
+		_, _ = b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+			CallbackQueryID: u.CallbackQuery.ID,
+			Text:            text,
+			ShowAlert:       true,
+		})
+		return
+	}
+
+	_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: getChatIDFromUpdate(u),
+		Text:   text,
+	})
+}
+
 // getUserPhotoURL fetches the direct URL to the user's largest profile photo.
 func getUserPhotoURL(ctx context.Context, b *bot.Bot, userID int64, botToken string, lg logger.TgLogger) string {
 	photos, err := b.GetUserProfilePhotos(ctx, &bot.GetUserProfilePhotosParams{
@@ -0,0 +1,222 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/botapp/commands"
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/archnets/telegram-bot/internal/notify"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// BalanceCallbackPrefix is the callback data prefix for balance top-up.
+const BalanceCallbackPrefix = "bal:"
+
+// StateTopUpAmount waits for a custom top-up amount.
+const StateTopUpAmount = "user.balance.amount"
+
+// maxTopUp is the largest top-up accepted in one order (cents).
+const maxTopUp = 1_000_000
+
+// topUpAmounts are the preset top-up amounts (cents).
+var topUpAmounts = []int64{500, 1000, 2000, 5000}
+
+// HandleBalance handles the /balance command.
+// Note: Authentication is handled by middleware.
+func HandleBalance(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.Message == nil {
+		return
+	}
+
+	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
+	loc := i18n.Localizer(lang)
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		info, err := deps.API.GetUserInfo(ctx, token)
+		if err != nil {
+			return err
+		}
+
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    u.Message.Chat.ID,
+			Text:      i18n.TWithData(loc, "balance_view", map[string]any{"Balance": i18n.FormatAmount(info.Balance)}),
+			ParseMode: models.ParseModeHTML,
+			ReplyMarkup: &models.InlineKeyboardMarkup{
+				InlineKeyboard: [][]models.InlineKeyboardButton{{
+					{Text: i18n.T(loc, "balance_topup_button"), CallbackData: BalanceCallbackPrefix + "topup"},
+				}},
+			},
+		})
+		return nil
+	})
+}
+
+// HandleBalanceCallback handles the top-up steps.
+// Callback data: "bal:topup", "bal:amt:<cents>", "bal:pay:<cents>:<method>".
+func HandleBalanceCallback(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.CallbackQuery == nil {
+		return
+	}
+	cb := u.CallbackQuery
+	lang := GetLanguage(ctx, cb.From.ID, cb.From.LanguageCode, deps)
+
+	parts := strings.Split(strings.TrimPrefix(cb.Data, BalanceCallbackPrefix), ":")
+	answerCallback(ctx, b, cb.ID, "", false)
+
+	switch parts[0] {
+	case "topup":
+		if err := deps.Conversations.Enter(cb.From.ID, StateTopUpAmount, nil); err != nil {
+			logger.ForUser(cb.From.ID).Errorf("Enter %s failed: %v", StateTopUpAmount, err)
+		}
+		text, keyboard := topUpAmountsView(lang)
+		editView(ctx, b, cb, text, keyboard)
+
+	case "amt":
+		if len(parts) < 2 {
+			return
+		}
+		amount, _ := strconv.ParseInt(parts[1], 10, 64)
+		deps.Conversations.End(cb.From.ID)
+		ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+			text, keyboard, err := topUpMethodsView(ctx, token, amount, lang, deps)
+			if err != nil {
+				return err
+			}
+			editView(ctx, b, cb, text, keyboard)
+			return nil
+		})
+
+	case "pay":
+		if len(parts) < 3 {
+			return
+		}
+		amount, _ := strconv.ParseInt(parts[1], 10, 64)
+		methodID, _ := strconv.ParseInt(parts[2], 10, 64)
+		ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+			return placeRecharge(ctx, b, cb, token, amount, methodID, lang, deps)
+		})
+	}
+}
+
+// HandleTopUpAmount receives a custom top-up amount, e.g. "12.50".
+func HandleTopUpAmount(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps, conv *commands.Conversation) {
+	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
+
+	amount, ok := parseAmount(u.Message.Text)
+	if !ok {
+		SendError(ctx, b, u.Message.Chat.ID, lang, "balance_invalid_amount")
+		return
+	}
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		text, keyboard, err := topUpMethodsView(ctx, token, amount, lang, deps)
+		if err != nil {
+			return err
+		}
+		deps.Conversations.End(u.Message.From.ID)
+
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:      u.Message.Chat.ID,
+			Text:        text,
+			ParseMode:   models.ParseModeHTML,
+			ReplyMarkup: keyboard,
+		})
+		return nil
+	})
+}
+
+// placeRecharge creates the recharge order, hands off to checkout and starts watching it.
+func placeRecharge(ctx context.Context, b *bot.Bot, cb *models.CallbackQuery, token string, amount, methodID int64, lang string, deps commands.Deps) error {
+	release, ok := claimButtons(ctx, b, cb)
+	if !ok {
+		return nil // Another press is placing the order
+	}
+
+	orderNo, err := deps.API.Recharge(ctx, token, api.OrderRequest{Amount: amount, Payment: methodID})
+	if err != nil {
+		if rejected(err) {
+			release()
+		}
+		return handlePurchaseError(ctx, b, cb.From.ID, lang, err)
+	}
+	logger.ForUser(cb.From.ID).Infof("Recharge order %s placed (%s)", orderNo, i18n.FormatAmount(amount))
+
+	method := findPaymentMethod(ctx, token, methodID, deps)
+	title := i18n.TWithData(i18n.Localizer(lang), "stars_title_topup", map[string]any{"Amount": i18n.FormatAmount(amount)})
+	if err := sendCheckout(ctx, b, cb.From.ID, token, orderNo, method, title, lang, deps); err != nil {
+		return err
+	}
+
+	deps.Orders.Watch(notify.WatchRequest{
+		TelegramID:    cb.From.ID,
+		Token:         token,
+		OrderNo:       orderNo,
+		PaymentMethod: paymentName(method, lang),
+	})
+	return nil
+}
+
+func topUpAmountsView(lang string) (string, *models.InlineKeyboardMarkup) {
+	loc := i18n.Localizer(lang)
+
+	var row []models.InlineKeyboardButton
+	for _, amount := range topUpAmounts {
+		row = append(row, models.InlineKeyboardButton{
+			Text:         i18n.FormatAmount(amount),
+			CallbackData: fmt.Sprintf("%samt:%d", BalanceCallbackPrefix, amount),
+		})
+	}
+
+	return i18n.T(loc, "balance_choose_amount"), &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{row},
+	}
+}
+
+// topUpMethodsView lists the payment methods for a top-up; balance can't pay for itself.
+func topUpMethodsView(ctx context.Context, token string, amount int64, lang string, deps commands.Deps) (string, *models.InlineKeyboardMarkup, error) {
+	loc := i18n.Localizer(lang)
+
+	methods, err := paymentMethods(ctx, token, deps)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var rows [][]models.InlineKeyboardButton
+	for _, m := range methods {
+		if m.Platform == api.PaymentPlatformBalance {
+			continue
+		}
+		rows = append(rows, []models.InlineKeyboardButton{{
+			Text:         "💳 " + paymentName(m, lang),
+			CallbackData: fmt.Sprintf("%spay:%d:%d", BalanceCallbackPrefix, amount, m.ID),
+		}})
+	}
+	if len(rows) == 0 {
+		return i18n.T(loc, "buy_payment_not_found"), nil, nil
+	}
+
+	text := i18n.TWithData(loc, "balance_choose_method", map[string]any{"Amount": i18n.FormatAmount(amount)})
+	return text, &models.InlineKeyboardMarkup{InlineKeyboard: rows}, nil
+}
+
+// parseAmount parses a decimal amount in major units into cents.
+func parseAmount(text string) (int64, bool) {
+	text = strings.ReplaceAll(strings.TrimSpace(text), ",", ".")
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, false
+	}
+
+	cents := int64(math.Round(value * 100))
+	if cents <= 0 || cents > maxTopUp {
+		return 0, false
+	}
+	return cents, true
+}
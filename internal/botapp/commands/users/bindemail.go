@@ -0,0 +1,118 @@
+package users
+
+import (
+	"context"
+	"html"
+	"net/mail"
+	"strings"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/botapp/commands"
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Conversation states of the email binding flow.
+const (
+	StateBindEmailAddress = "user.bindemail.address"
+	StateBindEmailCode    = "user.bindemail.code"
+)
+
+// HandleBindEmail handles the /bindemail command by asking for the email address.
+// Note: Authentication is handled by middleware.
+func HandleBindEmail(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.Message == nil {
+		return
+	}
+
+	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
+	startConversation(ctx, b, u.Message.From.ID, StateBindEmailAddress, nil, i18n.T(i18n.Localizer(lang), "bindemail_ask_email"), deps)
+}
+
+// HandleBindEmailAddress receives the email address and requests a verification code.
+func HandleBindEmailAddress(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps, conv *commands.Conversation) {
+	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
+	loc := i18n.Localizer(lang)
+
+	email, ok := parseEmail(u.Message.Text)
+	if !ok {
+		SendError(ctx, b, u.Message.Chat.ID, lang, "bindemail_invalid_email")
+		return
+	}
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		err := deps.API.SendEmailCode(ctx, token, email, api.VerifyCodeSecurity)
+		if err != nil {
+			return handleBindEmailError(ctx, b, u, deps, lang, err)
+		}
+
+		prompt := i18n.TWithData(loc, "bindemail_ask_code", map[string]any{"Email": html.EscapeString(email)})
+		if err := deps.Conversations.Enter(u.Message.From.ID, StateBindEmailCode, map[string]string{"email": email}); err != nil {
+			return err
+		}
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    u.Message.Chat.ID,
+			Text:      prompt,
+			ParseMode: models.ParseModeHTML,
+		})
+		logger.ForUpdate(u).Infof("Email verification code requested")
+		return nil
+	})
+}
+
+// HandleBindEmailCode receives the verification code and binds the email.
+func HandleBindEmailCode(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps, conv *commands.Conversation) {
+	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
+	loc := i18n.Localizer(lang)
+
+	code := strings.TrimSpace(u.Message.Text)
+	if code == "" {
+		SendError(ctx, b, u.Message.Chat.ID, lang, "bindemail_code_invalid")
+		return
+	}
+	email := conv.Data["email"]
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		if err := deps.API.BindEmail(ctx, token, email, code); err != nil {
+			return handleBindEmailError(ctx, b, u, deps, lang, err)
+		}
+		deps.Conversations.End(u.Message.From.ID)
+
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    u.Message.Chat.ID,
+			Text:      i18n.TWithData(loc, "bindemail_success", map[string]any{"Email": html.EscapeString(email)}),
+			ParseMode: models.ParseModeHTML,
+		})
+		logger.ForUpdate(u).Infof("Email bound")
+		return nil
+	})
+}
+
+// handleBindEmailError explains an error of the backend to the user, ending
+// the flow when retrying within it cannot help. Other errors, and rejected
+// sessions, are returned for generic handling.
+func handleBindEmailError(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps, lang string, err error) error {
+	code := api.ErrorCode(err)
+	if code == 0 || api.IsAuthError(code) {
+		return err
+	}
+
+	switch code {
+	case api.EmailNotEnabled, api.TodaySendCountExceedsLimit:
+		deps.Conversations.End(u.Message.From.ID)
+	}
+	commands.SendAPIError(ctx, b, u.Message.Chat.ID, lang, err)
+	return nil
+}
+
+// parseEmail validates a bare email address.
+func parseEmail(text string) (string, bool) {
+	text = strings.TrimSpace(text)
+	addr, err := mail.ParseAddress(text)
+	if err != nil || addr.Address != text {
+		return "", false
+	}
+	return addr.Address, true
+}
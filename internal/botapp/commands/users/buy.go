@@ -0,0 +1,470 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/botapp/commands"
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/archnets/telegram-bot/internal/notify"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// BuyCallbackPrefix is the callback data prefix for the purchase flow.
+const BuyCallbackPrefix = "buy:"
+
+// StateBuy is the conversation state of the purchase flow.
+// It holds the selection (plan, qty, coupon); text messages are coupon codes.
+const StateBuy = "user.buy"
+
+// maxDescription is the maximum plan description length shown.
+const maxDescription = 300
+
+// HandleBuy handles the /buy command by listing purchasable plans.
+// Note: Authentication is handled by middleware.
+func HandleBuy(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.Message == nil {
+		return
+	}
+
+	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		plans, err := purchasablePlans(ctx, token, deps)
+		if err != nil {
+			return err
+		}
+
+		text, keyboard := planListView(plans, lang)
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:      u.Message.Chat.ID,
+			Text:        text,
+			ParseMode:   models.ParseModeHTML,
+			ReplyMarkup: keyboard,
+		})
+		return nil
+	})
+}
+
+// HandleBuyCallback handles the purchase flow steps.
+// Callback data: "buy:list", "buy:plan:<id>", "buy:qty:<n>", "buy:pay:<method>", "buy:cancel".
+func HandleBuyCallback(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.CallbackQuery == nil {
+		return
+	}
+	cb := u.CallbackQuery
+	lang := GetLanguage(ctx, cb.From.ID, cb.From.LanguageCode, deps)
+	loc := i18n.Localizer(lang)
+
+	action, arg, _ := strings.Cut(strings.TrimPrefix(cb.Data, BuyCallbackPrefix), ":")
+	n, _ := strconv.ParseInt(arg, 10, 64)
+
+	// Steps after choosing a plan need the selection from the conversation
+	var conv *commands.Conversation
+	if action == "qty" || action == "pay" {
+		var ok bool
+		if conv, ok = deps.Conversations.Get(cb.From.ID); !ok || conv.State != StateBuy {
+			answerCallback(ctx, b, cb.ID, i18n.T(loc, "conversation_expired"), true)
+			return
+		}
+	}
+	answerCallback(ctx, b, cb.ID, "", false)
+
+	switch action {
+	case "list":
+		coupon := pendingCoupon(cb.From.ID, deps)
+		ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+			plans, err := purchasablePlans(ctx, token, deps)
+			if err != nil {
+				return err
+			}
+			if err := resetPurchase(cb.From.ID, coupon, deps); err != nil {
+				return err
+			}
+			text, keyboard := planListView(plans, lang)
+			editView(ctx, b, cb, text, keyboard)
+			return nil
+		})
+
+	case "plan":
+		ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+			plan, err := findPlan(ctx, token, n, deps)
+			if err != nil {
+				return err
+			}
+			data := map[string]string{"plan": arg}
+			if coupon := pendingCoupon(cb.From.ID, deps); coupon != "" {
+				data["coupon"] = coupon
+			}
+			if err := deps.Conversations.Enter(cb.From.ID, StateBuy, data); err != nil {
+				return err
+			}
+			text, keyboard := planView(plan, lang)
+			editView(ctx, b, cb, text, keyboard)
+			return nil
+		})
+
+	case "qty":
+		conv.Data["qty"] = arg
+		ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+			text, keyboard, err := orderSummary(ctx, token, conv, lang, deps)
+			if api.IsCouponError(api.ErrorCode(err)) && conv.Data["coupon"] != "" {
+				// Continue without the coupon, explaining why it was dropped
+				commands.SendAPIError(ctx, b, cb.From.ID, lang, err)
+				delete(conv.Data, "coupon")
+				text, keyboard, err = orderSummary(ctx, token, conv, lang, deps)
+			}
+			if err != nil {
+				return handlePurchaseError(ctx, b, cb.From.ID, lang, err)
+			}
+			if err := deps.Conversations.Enter(cb.From.ID, StateBuy, conv.Data); err != nil {
+				return err
+			}
+			editView(ctx, b, cb, text, keyboard)
+			return nil
+		})
+
+	case "pay":
+		ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+			return placeOrder(ctx, b, cb, token, conv, n, lang, deps)
+		})
+
+	case "cancel":
+		deps.Conversations.End(cb.From.ID)
+		editView(ctx, b, cb, i18n.T(loc, "conversation_cancelled"), nil)
+	}
+}
+
+// HandleBuyCoupon receives a coupon code during the purchase flow.
+func HandleBuyCoupon(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps, conv *commands.Conversation) {
+	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
+
+	if conv.Data["qty"] == "" {
+		SendError(ctx, b, u.Message.Chat.ID, lang, "buy_choose_period_first")
+		return
+	}
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		return previewCoupon(ctx, b, u.Message.From.ID, token, conv, u.Message.Text, lang, deps)
+	})
+}
+
+// placeOrder creates the order, hands off to checkout and starts watching it.
+func placeOrder(ctx context.Context, b *bot.Bot, cb *models.CallbackQuery, token string, conv *commands.Conversation, methodID int64, lang string, deps commands.Deps) error {
+	userID := cb.From.ID
+	lg := logger.ForUser(userID)
+
+	release, ok := claimButtons(ctx, b, cb)
+	if !ok {
+		return nil // Another press is placing the order
+	}
+
+	planID, _ := strconv.ParseInt(conv.Data["plan"], 10, 64)
+	plan, err := findPlan(ctx, token, planID, deps)
+	if err != nil {
+		release()
+		return err
+	}
+	req := orderRequest(conv)
+	req.Payment = methodID
+	orderNo, err := deps.API.Purchase(ctx, token, req)
+	if err != nil {
+		if rejected(err) {
+			release()
+		}
+		return handlePurchaseError(ctx, b, userID, lang, err)
+	}
+	deps.Conversations.End(userID)
+	lg.Infof("Order %s placed for plan %d", orderNo, plan.ID)
+
+	method := findPaymentMethod(ctx, token, methodID, deps)
+	if err := sendCheckout(ctx, b, userID, token, orderNo, method, plan.Name, lang, deps); err != nil {
+		return err
+	}
+
+	deps.Orders.Watch(notify.WatchRequest{
+		TelegramID:    userID,
+		Token:         token,
+		OrderNo:       orderNo,
+		SubscribeID:   plan.ID,
+		SubscribeName: plan.Name,
+		PaymentMethod: paymentName(method, lang),
+	})
+	return nil
+}
+
+// sendCheckout sends the payment link of an order, or a processing notice
+// if no redirect is needed (e.g. paid from balance). Stars orders get an
+// invoice titled title instead.
+func sendCheckout(ctx context.Context, b *bot.Bot, chatID int64, token, orderNo string, method api.PaymentMethod, title, lang string, deps commands.Deps) error {
+	if method.Platform == api.PaymentPlatformStars {
+		return sendStarsInvoice(ctx, b, chatID, token, orderNo, title, lang, deps)
+	}
+
+	loc := i18n.Localizer(lang)
+
+	checkout, err := deps.API.CheckoutOrder(ctx, token, orderNo)
+	if err != nil {
+		return handlePurchaseError(ctx, b, chatID, lang, err)
+	}
+
+	params := &bot.SendMessageParams{
+		ChatID:    chatID,
+		Text:      i18n.TWithData(loc, "buy_processing", map[string]any{"OrderNo": html.EscapeString(orderNo)}),
+		ParseMode: models.ParseModeHTML,
+	}
+	if checkout.CheckoutURL != "" {
+		params.Text = i18n.TWithData(loc, "buy_checkout", map[string]any{"OrderNo": html.EscapeString(orderNo)})
+		params.ReplyMarkup = &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{{
+				{Text: i18n.T(loc, "buy_pay_button"), URL: checkout.CheckoutURL},
+			}},
+		}
+	}
+
+	_, _ = b.SendMessage(ctx, params)
+	return nil
+}
+
+// handlePurchaseError explains a purchase the backend rejected to the user.
+// Other errors, and rejected sessions, are returned for generic handling.
+func handlePurchaseError(ctx context.Context, b *bot.Bot, chatID int64, lang string, err error) error {
+	code := api.ErrorCode(err)
+	if code == 0 || api.IsAuthError(code) {
+		return err
+	}
+	logger.ForUser(chatID).Warnf("Purchase rejected: %v", err)
+	commands.SendAPIError(ctx, b, chatID, lang, err)
+	return nil
+}
+
+// purchasablePlans returns the plans currently on sale.
+func purchasablePlans(ctx context.Context, token string, deps commands.Deps) ([]api.Subscribe, error) {
+	plans, err := deps.API.GetSubscribeList(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(plans, func(p api.Subscribe) bool {
+		return !p.Sell || p.Inventory == 0
+	}), nil
+}
+
+func findPlan(ctx context.Context, token string, id int64, deps commands.Deps) (*api.Subscribe, error) {
+	plans, err := deps.API.GetSubscribeList(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range plans {
+		if p.ID == id {
+			return &p, nil
+		}
+	}
+	return nil, &api.Error{Code: api.SubscribeNotAvailable, Message: "plan not found"}
+}
+
+// paymentMethods returns the payment methods the user can choose from.
+// Stars are hidden unless the bot accepts them.
+func paymentMethods(ctx context.Context, token string, deps commands.Deps) ([]api.PaymentMethod, error) {
+	methods, err := deps.API.GetPaymentMethods(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if deps.Stars.Enabled() {
+		return methods, nil
+	}
+	return slices.DeleteFunc(methods, func(m api.PaymentMethod) bool {
+		return m.Platform == api.PaymentPlatformStars
+	}), nil
+}
+
+// findPaymentMethod returns a payment method by ID.
+// If the methods can't be fetched, only the ID is set.
+func findPaymentMethod(ctx context.Context, token string, id int64, deps commands.Deps) api.PaymentMethod {
+	methods, err := deps.API.GetPaymentMethods(ctx, token)
+	if err == nil {
+		for _, m := range methods {
+			if m.ID == id {
+				return m
+			}
+		}
+	}
+	return api.PaymentMethod{ID: id}
+}
+
+// orderRequest builds the order request from the purchase selection.
+func orderRequest(conv *commands.Conversation) api.OrderRequest {
+	planID, _ := strconv.ParseInt(conv.Data["plan"], 10, 64)
+	qty, _ := strconv.ParseInt(conv.Data["qty"], 10, 64)
+	return api.OrderRequest{
+		SubscribeID: planID,
+		Quantity:    max(qty, 1),
+		Coupon:      conv.Data["coupon"],
+	}
+}
+
+// orderSummary previews the price of the selection and lists payment methods.
+func orderSummary(ctx context.Context, token string, conv *commands.Conversation, lang string, deps commands.Deps) (string, *models.InlineKeyboardMarkup, error) {
+	loc := i18n.Localizer(lang)
+	req := orderRequest(conv)
+
+	plan, err := findPlan(ctx, token, req.SubscribeID, deps)
+	if err != nil {
+		return "", nil, err
+	}
+	preview, err := deps.API.PreviewOrder(ctx, token, req)
+	if err != nil {
+		return "", nil, err
+	}
+	methods, err := paymentMethods(ctx, token, deps)
+	if err != nil {
+		return "", nil, err
+	}
+
+	lines := []string{
+		i18n.TWithData(loc, "buy_summary", map[string]any{
+			"Name":   html.EscapeString(plan.Name),
+			"Period": periodLabel(req.Quantity, plan.UnitTime, lang),
+			"Price":  i18n.FormatAmount(preview.Price),
+		}),
+	}
+	if preview.Discount > 0 {
+		lines = append(lines, i18n.TWithData(loc, "buy_summary_discount", map[string]any{"Amount": i18n.FormatAmount(preview.Discount)}))
+	}
+	if preview.CouponDiscount > 0 {
+		lines = append(lines, i18n.TWithData(loc, "buy_summary_coupon", map[string]any{
+			"Code":   html.EscapeString(req.Coupon),
+			"Amount": i18n.FormatAmount(preview.CouponDiscount),
+		}))
+	}
+	if preview.FeeAmount > 0 {
+		lines = append(lines, i18n.TWithData(loc, "buy_summary_fee", map[string]any{"Amount": i18n.FormatAmount(preview.FeeAmount)}))
+	}
+	lines = append(lines,
+		i18n.TWithData(loc, "buy_summary_total", map[string]any{"Total": i18n.FormatAmount(preview.Amount)}),
+		"",
+		i18n.T(loc, "buy_coupon_hint"),
+	)
+
+	var rows [][]models.InlineKeyboardButton
+	for _, m := range methods {
+		rows = append(rows, []models.InlineKeyboardButton{{
+			Text:         "💳 " + paymentName(m, lang),
+			CallbackData: fmt.Sprintf("%spay:%d", BuyCallbackPrefix, m.ID),
+		}})
+	}
+	rows = append(rows, []models.InlineKeyboardButton{
+		{Text: i18n.T(loc, "buy_back_button"), CallbackData: fmt.Sprintf("%splan:%d", BuyCallbackPrefix, plan.ID)},
+		{Text: i18n.T(loc, "buy_cancel_button"), CallbackData: BuyCallbackPrefix + "cancel"},
+	})
+
+	return strings.Join(lines, "\n"), &models.InlineKeyboardMarkup{InlineKeyboard: rows}, nil
+}
+
+func planListView(plans []api.Subscribe, lang string) (string, *models.InlineKeyboardMarkup) {
+	loc := i18n.Localizer(lang)
+
+	if len(plans) == 0 {
+		return i18n.T(loc, "buy_no_plans"), nil
+	}
+
+	var rows [][]models.InlineKeyboardButton
+	for _, p := range plans {
+		rows = append(rows, []models.InlineKeyboardButton{{
+			Text: fmt.Sprintf("%s · %s / %s · %s", truncateLabel(p.Name, maxButtonLabel), i18n.FormatAmount(p.UnitPrice),
+				unitLabel(p.UnitTime, lang), trafficLabel(p.Traffic, lang)),
+			CallbackData: fmt.Sprintf("%splan:%d", BuyCallbackPrefix, p.ID),
+		}})
+	}
+
+	return i18n.T(loc, "buy_choose_plan"), &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+func planView(p *api.Subscribe, lang string) (string, *models.InlineKeyboardMarkup) {
+	loc := i18n.Localizer(lang)
+
+	text := i18n.TWithData(loc, "buy_plan_details", map[string]any{
+		"Name":        html.EscapeString(p.Name),
+		"Description": html.EscapeString(truncateLabel(p.Description, maxDescription)),
+		"Traffic":     trafficLabel(p.Traffic, lang),
+		"Price":       i18n.FormatAmount(p.UnitPrice),
+		"Unit":        unitLabel(p.UnitTime, lang),
+	})
+
+	// Single period plus every discounted quantity
+	quantities := []int64{1}
+	for _, d := range p.Discount {
+		if d.Quantity > 1 && !slices.Contains(quantities, d.Quantity) {
+			quantities = append(quantities, d.Quantity)
+		}
+	}
+	slices.Sort(quantities)
+
+	var rows [][]models.InlineKeyboardButton
+	var row []models.InlineKeyboardButton
+	for _, q := range quantities {
+		row = append(row, models.InlineKeyboardButton{
+			Text:         periodLabel(q, p.UnitTime, lang),
+			CallbackData: fmt.Sprintf("%sqty:%d", BuyCallbackPrefix, q),
+		})
+		if len(row) == 3 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	rows = append(rows, []models.InlineKeyboardButton{
+		{Text: i18n.T(loc, "buy_back_button"), CallbackData: BuyCallbackPrefix + "list"},
+	})
+
+	return text, &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// periodLabel formats a quantity of plan periods, e.g. "3 × Month".
+func periodLabel(qty int64, unit, lang string) string {
+	return fmt.Sprintf("%d × %s", qty, unitLabel(unit, lang))
+}
+
+// unitLabel translates a plan period unit.
+func unitLabel(unit, lang string) string {
+	loc := i18n.Localizer(lang)
+	switch unit {
+	case api.UnitTimeDay:
+		return i18n.T(loc, "unit_day")
+	case api.UnitTimeMonth:
+		return i18n.T(loc, "unit_month")
+	case api.UnitTimeYear:
+		return i18n.T(loc, "unit_year")
+	}
+	return unit
+}
+
+// trafficLabel formats a traffic quota; zero means unlimited.
+func trafficLabel(bytes int64, lang string) string {
+	if bytes == 0 {
+		return i18n.T(i18n.Localizer(lang), "buy_unlimited")
+	}
+	return i18n.FormatBytes(bytes)
+}
+
+// paymentName returns the display name of a payment method.
+func paymentName(m api.PaymentMethod, lang string) string {
+	switch m.Platform {
+	case api.PaymentPlatformBalance:
+		return i18n.T(i18n.Localizer(lang), "payment_balance")
+	case api.PaymentPlatformStars:
+		return i18n.T(i18n.Localizer(lang), "payment_stars")
+	}
+	if m.Name != "" {
+		return m.Name
+	}
+	return "#" + strconv.FormatInt(m.ID, 10)
+}
@@ -0,0 +1,33 @@
+package users
+
+import (
+	"context"
+
+	"github.com/archnets/telegram-bot/internal/botapp/commands"
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// HandleCancel handles the /cancel command by ending the active multi-step flow.
+func HandleCancel(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.Message == nil {
+		return
+	}
+
+	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
+	loc := i18n.Localizer(lang)
+
+	key := "nothing_to_cancel"
+	if conv, ok := deps.Conversations.Get(u.Message.From.ID); ok {
+		deps.Conversations.End(u.Message.From.ID)
+		logger.ForUpdate(u).Infof("Conversation %s cancelled", conv.State)
+		key = "conversation_cancelled"
+	}
+
+	_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: u.Message.Chat.ID,
+		Text:   i18n.T(loc, key),
+	})
+}
@@ -0,0 +1,79 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// claimTTL is how long a pressed payment button stays claimed. Presses
+// queued behind the first one arrive well within it.
+const claimTTL = time.Hour
+
+// claims holds the messages whose payment buttons were pressed. Handlers run
+// concurrently, so a double tap must be dropped before any order is placed.
+var claims = struct {
+	sync.Mutex
+	pressed map[string]time.Time
+}{pressed: make(map[string]time.Time)}
+
+// claimButtons claims the message of a payment button press and removes its
+// keyboard. It returns false if the message was already claimed, in which
+// case the press must be dropped. Call release if the action failed without
+// effect, to put the buttons back.
+func claimButtons(ctx context.Context, b *bot.Bot, cb *models.CallbackQuery) (release func(), ok bool) {
+	key := fmt.Sprintf("%d:%s", cb.From.ID, cb.Data)
+	msg := cb.Message.Message
+	if msg != nil {
+		key = fmt.Sprintf("%d:%d", msg.Chat.ID, msg.ID)
+	} else if m := cb.Message.InaccessibleMessage; m != nil {
+		key = fmt.Sprintf("%d:%d", m.Chat.ID, m.MessageID)
+	}
+
+	now := time.Now()
+	claims.Lock()
+	for k, at := range claims.pressed {
+		if now.Sub(at) > claimTTL {
+			delete(claims.pressed, k)
+		}
+	}
+	if _, taken := claims.pressed[key]; taken {
+		claims.Unlock()
+		return nil, false
+	}
+	claims.pressed[key] = now
+	claims.Unlock()
+
+	if msg != nil {
+		_, _ = b.EditMessageReplyMarkup(ctx, &bot.EditMessageReplyMarkupParams{
+			ChatID:    msg.Chat.ID,
+			MessageID: msg.ID,
+		})
+	}
+
+	release = func() {
+		claims.Lock()
+		delete(claims.pressed, key)
+		claims.Unlock()
+
+		if msg != nil && msg.ReplyMarkup != nil {
+			_, _ = b.EditMessageReplyMarkup(ctx, &bot.EditMessageReplyMarkupParams{
+				ChatID:      msg.Chat.ID,
+				MessageID:   msg.ID,
+				ReplyMarkup: msg.ReplyMarkup,
+			})
+		}
+	}
+	return release, true
+}
+
+// rejected returns true if the backend refused a request, so it had no effect.
+// Other failures (timeouts, gateway errors) may have been applied anyway.
+func rejected(err error) bool {
+	return api.ErrorCode(err) != 0
+}
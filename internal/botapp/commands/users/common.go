@@ -2,7 +2,6 @@ package users
 
 import (
 	"context"
-	"time"
 
 	"github.com/archnets/telegram-bot/internal/api"
 	"github.com/archnets/telegram-bot/internal/auth"
@@ -13,78 +12,23 @@ import (
 	"github.com/go-telegram/bot/models"
 )
 
+// maxButtonLabel is the maximum length of user-provided text on an inline button.
+const maxButtonLabel = 32
+
 // Authenticate authenticates the user with the backend API.
 // It forces a token refresh and preserves the existing language setting.
-func Authenticate(ctx context.Context, b *bot.Bot, user *models.User, deps commands.Deps, lg logger.TgLogger) (string, error) {
-	// Preserve existing language
-	existingLang := deps.Sessions.GetLang(user.ID)
-
-	// Fetch user's profile photo URL
-	photoURL := getUserPhotoURL(ctx, b, user.ID, deps.BotToken, lg)
-
-	token, err := deps.AuthClient.Authenticate(auth.TelegramUser{
-		ID:           user.ID,
-		Username:     user.Username,
-		FirstName:    user.FirstName,
-		LastName:     user.LastName,
-		LanguageCode: user.LanguageCode,
-		PhotoURL:     photoURL,
-	})
-	if err != nil {
-		lg.Errorf("Auth failed: %v", err)
-		return "", err
-	}
-
-	// Save session with new token but preserve language
-	session := &auth.Session{
-		Token:     token,
-		Lang:      existingLang,
-		ExpiresAt: time.Now().Add(7 * 24 * time.Hour),
-	}
-	deps.Sessions.Set(user.ID, session)
-
-	lg.Infof("User authenticated (forced refresh)")
-	return token, nil
+func Authenticate(ctx context.Context, user *models.User, deps commands.Deps) (string, error) {
+	return authenticate(ctx, user, "", deps)
 }
 
-// getUserPhotoURL fetches the direct URL to the user's largest profile photo.
-// Returns empty string if the user has no photo or if fetching fails.
-func getUserPhotoURL(ctx context.Context, b *bot.Bot, userID int64, botToken string, lg logger.TgLogger) string {
-	photos, err := b.GetUserProfilePhotos(ctx, &bot.GetUserProfilePhotosParams{
-		UserID: userID,
-		Limit:  1,
-	})
+// authenticate is Authenticate with an optional referral code for new accounts.
+func authenticate(ctx context.Context, user *models.User, inviteCode string, deps commands.Deps) (string, error) {
+	token, err := deps.Tokens.Login(ctx, commands.TelegramUser(user, inviteCode))
 	if err != nil {
-		lg.Debugf("Failed to get profile photos: %v", err)
-		return ""
-	}
-
-	if photos.TotalCount == 0 || len(photos.Photos) == 0 {
-		return ""
-	}
-
-	// Get the largest size from the first photo
-	photoSizes := photos.Photos[0]
-	if len(photoSizes) == 0 {
-		return ""
-	}
-	largestPhoto := photoSizes[len(photoSizes)-1]
-
-	// Get direct download URL
-	file, err := b.GetFile(ctx, &bot.GetFileParams{
-		FileID: largestPhoto.FileID,
-	})
-	if err != nil {
-		lg.Debugf("Failed to get file info: %v", err)
-		return ""
-	}
-
-	if file.FilePath == "" {
-		return ""
+		logger.ForUser(user.ID).Errorf("Auth failed: %v", err)
+		return "", err
 	}
-
-	// Build direct URL: https://api.telegram.org/file/bot<token>/<file_path>
-	return "https://api.telegram.org/file/bot" + botToken + "/" + file.FilePath
+	return token, nil
 }
 
 // GetLanguage retrieves the user's language preference.
@@ -127,55 +71,90 @@ func SendError(ctx context.Context, b *bot.Bot, chatID int64, lang, key string)
 	})
 }
 
-// ExecuteWithAuth executes an API action with automatic token refresh.
-// It handles token retrieval, initial execution, and retry on auth error.
+// ExecuteWithAuth executes an API action with the user's token.
+// Expired tokens are refreshed by the API client, which retries the request once;
+// if the backend still rejects the session, the user is asked to start over.
+// Works for both messages and callback queries.
 func ExecuteWithAuth(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps, action func(token string) error) {
-	if u.Message == nil {
+	user, chatID := updateSender(u)
+	if user == nil {
 		return
 	}
-	user := u.Message.From
 	lang := GetLanguage(ctx, user.ID, user.LanguageCode, deps)
-	token := deps.Sessions.GetToken(user.ID)
-	lg := logger.ForUpdate(u)
+	lg := logger.ForUser(user.ID)
 
-	// If no token, try initial auth
-	if token == "" {
-		lg.Debugf("No token found, authenticating...")
-		var err error
-		token, err = Authenticate(ctx, b, user, deps, lg)
-		if err != nil {
-			SendError(ctx, b, u.Message.Chat.ID, lang, "auth_error")
-			return
-		}
+	token, err := deps.Tokens.Token(auth.WithUser(ctx, commands.TelegramUser(user, "")), user.ID)
+	if err != nil {
+		lg.Errorf("Auth failed: %v", err)
+		sendAuthError(ctx, b, chatID, lang, err)
+		return
 	}
 
-	// Execute action
-	err := action(token)
-	if err == nil {
+	err = action(token)
+	switch {
+	case err == nil:
 		return
+	case api.IsUnavailable(err):
+		lg.Warnf("Backend unavailable: %v", err)
+	case api.IsAuthError(api.ErrorCode(err)):
+		lg.Warnf("Session rejected after refresh: %v", err)
+		deps.Sessions.Delete(user.ID)
+	default:
+		lg.Errorf("Action failed: %v", err)
 	}
+	commands.SendAPIError(ctx, b, chatID, lang, err)
+}
 
-	// Check if it's an auth error
-	if apiErr, ok := err.(*api.Error); ok && api.IsAuthError(apiErr.Code) {
-		lg.Infof("Token expired, refreshing...")
+// sendAuthError explains a failed login: backend errors are translated,
+// anything else gets the generic authentication error.
+func sendAuthError(ctx context.Context, b *bot.Bot, chatID int64, lang string, err error) {
+	if api.ErrorCode(err) != 0 || api.IsUnavailable(err) {
+		commands.SendAPIError(ctx, b, chatID, lang, err)
+		return
+	}
+	SendError(ctx, b, chatID, lang, "auth_error")
+}
 
-		// Refresh token
-		newToken, authErr := Authenticate(ctx, b, user, deps, lg)
-		if authErr != nil {
-			deps.Sessions.Delete(user.ID)
-			SendError(ctx, b, u.Message.Chat.ID, lang, "session_expired")
-			return
-		}
+// updateSender returns the user and chat ID of a message or callback query update.
+func updateSender(u *models.Update) (*models.User, int64) {
+	switch {
+	case u.Message != nil:
+		return u.Message.From, u.Message.Chat.ID
+	case u.CallbackQuery != nil && u.CallbackQuery.Message.Message != nil:
+		return &u.CallbackQuery.From, u.CallbackQuery.Message.Message.Chat.ID
+	case u.CallbackQuery != nil:
+		return &u.CallbackQuery.From, u.CallbackQuery.From.ID
+	}
+	return nil, 0
+}
 
-		// Retry action with new token
-		if err := action(newToken); err != nil {
-			lg.Errorf("Retry failed: %v", err)
-			SendError(ctx, b, u.Message.Chat.ID, lang, "traffic_error") // Generic error
-		}
+// editView replaces the callback's message with an HTML text and keyboard.
+// Falls back to a new message if the original is no longer accessible.
+func editView(ctx context.Context, b *bot.Bot, cb *models.CallbackQuery, text string, keyboard *models.InlineKeyboardMarkup) {
+	if cb.Message.Message == nil {
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:      cb.From.ID,
+			Text:        text,
+			ParseMode:   models.ParseModeHTML,
+			ReplyMarkup: keyboard,
+		})
 		return
 	}
 
-	// Other errors
-	lg.Errorf("Action failed: %v", err)
-	SendError(ctx, b, u.Message.Chat.ID, lang, "traffic_error") // Generic error
+	_, _ = b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      cb.Message.Message.Chat.ID,
+		MessageID:   cb.Message.Message.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: keyboard,
+	})
+}
+
+// truncateLabel shortens s to at most n characters for use on a button.
+func truncateLabel(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
 }
@@ -0,0 +1,145 @@
+package users
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/botapp/commands"
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// ConfigCallbackPrefix is the callback data prefix for subscription link actions.
+const ConfigCallbackPrefix = "cfg:"
+
+// qrSize is the width and height of subscription QR codes in pixels.
+const qrSize = 512
+
+// HandleConfig sends the import link and QR code of each subscription.
+// Note: Authentication is handled by middleware.
+func HandleConfig(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.Message == nil {
+		return
+	}
+
+	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		subs, err := deps.API.GetUserSubscriptions(ctx, token)
+		if err != nil {
+			return err
+		}
+		if len(subs) == 0 {
+			SendError(ctx, b, u.Message.Chat.ID, lang, "no_subscriptions")
+			return nil
+		}
+
+		cfg, err := deps.API.GetSubscribeConfig(ctx)
+		if err != nil {
+			return err
+		}
+		for _, sub := range subs {
+			if err := sendSubscriptionLink(ctx, b, u.Message.Chat.ID, cfg, &sub, lang, deps); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// HandleConfigCallback handles subscription link resets.
+// Callback data: "cfg:reset:<id>" asks for confirmation, "cfg:confirm:<id>" resets the link,
+// "cfg:cancel" dismisses the confirmation.
+func HandleConfigCallback(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.CallbackQuery == nil {
+		return
+	}
+	cb := u.CallbackQuery
+	lang := GetLanguage(ctx, cb.From.ID, cb.From.LanguageCode, deps)
+	loc := i18n.Localizer(lang)
+
+	action, arg, _ := strings.Cut(strings.TrimPrefix(cb.Data, ConfigCallbackPrefix), ":")
+	subID, _ := strconv.ParseInt(arg, 10, 64)
+	answerCallback(ctx, b, cb.ID, "", false)
+
+	switch action {
+	case "reset":
+		ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+			sub, err := findUserSubscription(ctx, token, subID, deps)
+			if err != nil {
+				return err
+			}
+
+			_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID:    cb.From.ID,
+				Text:      i18n.TWithData(loc, "config_reset_confirm", map[string]any{"Name": html.EscapeString(subscriptionName(sub))}),
+				ParseMode: models.ParseModeHTML,
+				ReplyMarkup: &models.InlineKeyboardMarkup{
+					InlineKeyboard: [][]models.InlineKeyboardButton{{
+						{Text: i18n.T(loc, "config_reset_yes"), CallbackData: fmt.Sprintf("%sconfirm:%d", ConfigCallbackPrefix, sub.ID)},
+						{Text: i18n.T(loc, "buy_cancel_button"), CallbackData: ConfigCallbackPrefix + "cancel"},
+					}},
+				},
+			})
+			return nil
+		})
+
+	case "confirm":
+		ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+			if err := deps.API.ResetSubscribeToken(ctx, token, subID); err != nil {
+				return err
+			}
+			logger.ForUser(cb.From.ID).Infof("Subscription %d link reset", subID)
+			deleteMessage(ctx, b, cb)
+
+			// Send the new link
+			sub, err := findUserSubscription(ctx, token, subID, deps)
+			if err != nil {
+				return err
+			}
+			cfg, err := deps.API.GetSubscribeConfig(ctx)
+			if err != nil {
+				return err
+			}
+			return sendSubscriptionLink(ctx, b, cb.From.ID, cfg, sub, lang, deps)
+		})
+
+	default:
+		deleteMessage(ctx, b, cb)
+	}
+}
+
+// sendSubscriptionLink sends a subscription's import URL as copyable text on its QR code.
+func sendSubscriptionLink(ctx context.Context, b *bot.Bot, chatID int64, cfg *api.SubscribeConfig, sub *api.UserSubscription, lang string, deps commands.Deps) error {
+	loc := i18n.Localizer(lang)
+	link := deps.API.SubscribeURL(cfg, sub.Token)
+
+	png, err := qrcode.Encode(link, qrcode.Medium, qrSize)
+	if err != nil {
+		return fmt.Errorf("encode qr code: %w", err)
+	}
+
+	_, err = b.SendPhoto(ctx, &bot.SendPhotoParams{
+		ChatID: chatID,
+		Photo:  &models.InputFileUpload{Filename: "subscription.png", Data: bytes.NewReader(png)},
+		Caption: i18n.TWithData(loc, "config_link", map[string]any{
+			"Name": html.EscapeString(subscriptionName(sub)),
+			"URL":  html.EscapeString(link),
+		}),
+		ParseMode: models.ParseModeHTML,
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{{
+				{Text: i18n.T(loc, "config_reset_button"), CallbackData: fmt.Sprintf("%sreset:%d", ConfigCallbackPrefix, sub.ID)},
+			}},
+		},
+	})
+	return err
+}
@@ -0,0 +1,123 @@
+package users
+
+import (
+	"context"
+	"html"
+	"strings"
+
+	"github.com/archnets/telegram-bot/internal/botapp/commands"
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// maxCouponLength is the longest coupon code accepted.
+const maxCouponLength = 64
+
+// HandleCoupon handles the /coupon CODE command.
+// Note: Authentication is handled by middleware.
+func HandleCoupon(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.Message == nil {
+		return
+	}
+
+	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
+
+	args := strings.Fields(u.Message.Text)
+	if len(args) != 2 {
+		SendError(ctx, b, u.Message.Chat.ID, lang, "coupon_usage")
+		return
+	}
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		return applyCoupon(ctx, b, u.Message.From.ID, token, args[1], lang, deps)
+	})
+}
+
+// applyCoupon adds a coupon to the purchase flow. If a period is already chosen
+// the discount is previewed right away; otherwise the user picks a plan first.
+func applyCoupon(ctx context.Context, b *bot.Bot, userID int64, token, code, lang string, deps commands.Deps) error {
+	if conv, ok := deps.Conversations.Get(userID); ok && conv.State == StateBuy && conv.Data["qty"] != "" {
+		return previewCoupon(ctx, b, userID, token, conv, code, lang, deps)
+	}
+
+	code, ok := parseCoupon(code)
+	if !ok {
+		SendError(ctx, b, userID, lang, "coupon_not_exist")
+		return nil
+	}
+
+	plans, err := purchasablePlans(ctx, token, deps)
+	if err != nil {
+		return err
+	}
+	if err := resetPurchase(userID, code, deps); err != nil {
+		return err
+	}
+
+	text, keyboard := planListView(plans, lang)
+	if len(plans) > 0 {
+		text = i18n.TWithData(i18n.Localizer(lang), "coupon_choose_plan", map[string]any{"Code": html.EscapeString(code)})
+	}
+	_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      userID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: keyboard,
+	})
+	return nil
+}
+
+// previewCoupon shows the order summary of the current selection with the coupon applied.
+// A rejected coupon is explained and the previous selection is kept.
+func previewCoupon(ctx context.Context, b *bot.Bot, userID int64, token string, conv *commands.Conversation, code, lang string, deps commands.Deps) error {
+	code, ok := parseCoupon(code)
+	if !ok {
+		SendError(ctx, b, userID, lang, "coupon_not_exist")
+		return nil
+	}
+	conv.Data["coupon"] = code
+
+	text, keyboard, err := orderSummary(ctx, token, conv, lang, deps)
+	if err != nil {
+		return handlePurchaseError(ctx, b, userID, lang, err)
+	}
+	if err := deps.Conversations.Enter(userID, StateBuy, conv.Data); err != nil {
+		return err
+	}
+
+	_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      userID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: keyboard,
+	})
+	return nil
+}
+
+// pendingCoupon returns the coupon of the user's purchase flow, if any.
+func pendingCoupon(userID int64, deps commands.Deps) string {
+	conv, ok := deps.Conversations.Get(userID)
+	if !ok || conv.State != StateBuy {
+		return ""
+	}
+	return conv.Data["coupon"]
+}
+
+// resetPurchase clears the purchase selection, keeping coupon (if any) for the next plan.
+func resetPurchase(userID int64, coupon string, deps commands.Deps) error {
+	if coupon == "" {
+		deps.Conversations.End(userID)
+		return nil
+	}
+	return deps.Conversations.Enter(userID, StateBuy, map[string]string{"coupon": coupon})
+}
+
+// parseCoupon trims a coupon code and checks it is a single word of sane length.
+func parseCoupon(text string) (string, bool) {
+	code := strings.TrimSpace(text)
+	if code == "" || len(code) > maxCouponLength || strings.ContainsAny(code, " \t\n") {
+		return "", false
+	}
+	return code, true
+}
@@ -0,0 +1,100 @@
+package users
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/archnets/telegram-bot/internal/botapp/commands"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Deep-link payload prefixes, e.g. t.me/<bot>?start=plan_12.
+const (
+	DeepLinkPlan   = "plan_"   // plan_<id> opens a plan in the purchase flow
+	DeepLinkCoupon = "coupon_" // coupon_<code> applies a coupon to the next purchase
+	DeepLinkTicket = "ticket_" // ticket_<id> opens a support ticket
+)
+
+// HandlePlanLink opens a plan from a "plan_<id>" deep link.
+func HandlePlanLink(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps, arg string) {
+	user, _ := updateSender(u)
+	if user == nil {
+		return
+	}
+	lang := GetLanguage(ctx, user.ID, user.LanguageCode, deps)
+
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		SendError(ctx, b, user.ID, lang, "buy_not_available")
+		return
+	}
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		plan, err := findPlan(ctx, token, id, deps)
+		if err != nil {
+			return handlePurchaseError(ctx, b, user.ID, lang, err)
+		}
+
+		data := map[string]string{"plan": arg}
+		if coupon := pendingCoupon(user.ID, deps); coupon != "" {
+			data["coupon"] = coupon
+		}
+		if err := deps.Conversations.Enter(user.ID, StateBuy, data); err != nil {
+			return err
+		}
+
+		text, keyboard := planView(plan, lang)
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:      user.ID,
+			Text:        text,
+			ParseMode:   models.ParseModeHTML,
+			ReplyMarkup: keyboard,
+		})
+		return nil
+	})
+}
+
+// HandleCouponLink applies the coupon of a "coupon_<code>" deep link.
+func HandleCouponLink(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps, arg string) {
+	user, _ := updateSender(u)
+	if user == nil {
+		return
+	}
+	lang := GetLanguage(ctx, user.ID, user.LanguageCode, deps)
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		return applyCoupon(ctx, b, user.ID, token, arg, lang, deps)
+	})
+}
+
+// HandleTicketLink opens a support ticket from a "ticket_<id>" deep link.
+func HandleTicketLink(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps, arg string) {
+	user, _ := updateSender(u)
+	if user == nil {
+		return
+	}
+	lang := GetLanguage(ctx, user.ID, user.LanguageCode, deps)
+
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		SendError(ctx, b, user.ID, lang, "ticket_not_found")
+		return
+	}
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		ticket, err := deps.API.GetTicket(ctx, token, id)
+		if err != nil {
+			return err
+		}
+
+		text, keyboard := ticketView(ticket, lang)
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:      user.ID,
+			Text:        text,
+			ParseMode:   models.ParseModeHTML,
+			ReplyMarkup: keyboard,
+		})
+		return nil
+	})
+}
@@ -15,7 +15,11 @@ func DefaultHandler(ctx context.Context, b *bot.Bot, u *models.Update, deps comm
 		return
 	}
 
-	// Use saved language if available, otherwise Telegram's
+	// Free-text input for an active multi-step flow
+	if deps.Conversations.Dispatch(ctx, b, u, deps) {
+		return
+	}
+
 	// Use saved language if available, otherwise Telegram's
 	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
 
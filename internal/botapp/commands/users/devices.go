@@ -0,0 +1,184 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/botapp/commands"
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// DevicesCallbackPrefix is the callback data prefix for device actions.
+const DevicesCallbackPrefix = "device:"
+
+// HandleDevices handles the /devices command by listing the user's bound devices.
+// Note: Authentication is handled by middleware.
+func HandleDevices(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.Message == nil {
+		return
+	}
+
+	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		devices, err := deps.API.GetUserDevices(ctx, token)
+		if err != nil {
+			return err
+		}
+
+		text, keyboard := devicesView(devices, lang)
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:      u.Message.Chat.ID,
+			Text:        text,
+			ParseMode:   models.ParseModeHTML,
+			ReplyMarkup: keyboard,
+		})
+		return nil
+	})
+}
+
+// HandleDevicesCallback handles device actions.
+// Callback data: "device:list", "device:ask:<id>", "device:unbind:<id>".
+func HandleDevicesCallback(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.CallbackQuery == nil {
+		return
+	}
+	cb := u.CallbackQuery
+	lang := GetLanguage(ctx, cb.From.ID, cb.From.LanguageCode, deps)
+	loc := i18n.Localizer(lang)
+
+	action, arg, _ := strings.Cut(strings.TrimPrefix(cb.Data, DevicesCallbackPrefix), ":")
+	id, _ := strconv.ParseInt(arg, 10, 64)
+
+	switch action {
+	case "list":
+		answerCallback(ctx, b, cb.ID, "", false)
+		ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+			return refreshDevices(ctx, b, cb, token, lang, deps)
+		})
+
+	case "ask":
+		answerCallback(ctx, b, cb.ID, "", false)
+		ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+			devices, err := deps.API.GetUserDevices(ctx, token)
+			if err != nil {
+				return err
+			}
+			for _, d := range devices {
+				if d.ID == id {
+					text, keyboard := deviceConfirmView(&d, lang)
+					editView(ctx, b, cb, text, keyboard)
+					return nil
+				}
+			}
+			// Already gone, show the current list
+			text, keyboard := devicesView(devices, lang)
+			editView(ctx, b, cb, text, keyboard)
+			return nil
+		})
+
+	case "unbind":
+		ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+			err := deps.API.UnbindDevice(ctx, token, id)
+			switch {
+			case api.ErrorCode(err) == api.DeviceNotExist:
+				answerCallback(ctx, b, cb.ID, i18n.T(loc, "device_not_found"), true)
+			case err != nil:
+				answerCallback(ctx, b, cb.ID, "", false)
+				return err
+			default:
+				answerCallback(ctx, b, cb.ID, i18n.T(loc, "device_unbound"), false)
+				logger.ForUser(cb.From.ID).Infof("Device %d unbound", id)
+			}
+			return refreshDevices(ctx, b, cb, token, lang, deps)
+		})
+
+	default:
+		answerCallback(ctx, b, cb.ID, "", false)
+	}
+}
+
+// refreshDevices replaces the callback's message with the current device list.
+func refreshDevices(ctx context.Context, b *bot.Bot, cb *models.CallbackQuery, token, lang string, deps commands.Deps) error {
+	devices, err := deps.API.GetUserDevices(ctx, token)
+	if err != nil {
+		return err
+	}
+	text, keyboard := devicesView(devices, lang)
+	editView(ctx, b, cb, text, keyboard)
+	return nil
+}
+
+func devicesView(devices []api.Device, lang string) (string, *models.InlineKeyboardMarkup) {
+	loc := i18n.Localizer(lang)
+
+	if len(devices) == 0 {
+		return i18n.T(loc, "devices_empty"), nil
+	}
+
+	msg := i18n.TWithData(loc, "devices_title", map[string]any{"Count": len(devices)}) + "\n\n"
+
+	var rows [][]models.InlineKeyboardButton
+	for i, d := range devices {
+		state := i18n.TWithData(loc, "device_last_seen", map[string]any{
+			"Time": formatDeviceTime(d.UpdatedAt),
+		})
+		if d.Online {
+			state = i18n.T(loc, "device_online")
+		}
+
+		msg += fmt.Sprintf("<b>%d. %s</b>\n", i+1, html.EscapeString(deviceName(&d)))
+		if d.IP != "" {
+			msg += fmt.Sprintf("├ 🌐 <code>%s</code>\n", html.EscapeString(d.IP))
+		}
+		msg += fmt.Sprintf("└ %s\n\n", state)
+
+		rows = append(rows, []models.InlineKeyboardButton{{
+			Text:         fmt.Sprintf("❌ %d. %s", i+1, truncateLabel(deviceName(&d), maxButtonLabel)),
+			CallbackData: fmt.Sprintf("%sask:%d", DevicesCallbackPrefix, d.ID),
+		}})
+	}
+
+	return msg + i18n.T(loc, "devices_hint"), &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+func deviceConfirmView(d *api.Device, lang string) (string, *models.InlineKeyboardMarkup) {
+	loc := i18n.Localizer(lang)
+
+	text := i18n.TWithData(loc, "device_unbind_confirm", map[string]any{
+		"Name": html.EscapeString(deviceName(d)),
+	})
+
+	return text, &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{{
+			{Text: i18n.T(loc, "device_unbind_button"), CallbackData: fmt.Sprintf("%sunbind:%d", DevicesCallbackPrefix, d.ID)},
+			{Text: i18n.T(loc, "device_keep_button"), CallbackData: DevicesCallbackPrefix + "list"},
+		}},
+	}
+}
+
+// deviceName returns a human-readable name for a device.
+func deviceName(d *api.Device) string {
+	if d.Identifier != "" {
+		return d.Identifier
+	}
+	if d.UserAgent != "" {
+		return d.UserAgent
+	}
+	return "#" + strconv.FormatInt(d.ID, 10)
+}
+
+func formatDeviceTime(unixMs int64) string {
+	if unixMs == 0 {
+		return "—"
+	}
+	return time.UnixMilli(unixMs).Format("2006-01-02 15:04")
+}
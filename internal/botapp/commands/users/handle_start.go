@@ -9,25 +9,28 @@ import (
 	"github.com/go-telegram/bot/models"
 )
 
-// HandleStart handles the /start command.
+// HandleStart handles the /start command, optionally with a deep-link payload
+// (e.g. "/start plan_12"). The payload is routed once the user is set up.
 func HandleStart(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
 	if u.Message == nil {
 		return
 	}
 	lg := logger.ForUpdate(u)
 	user := u.Message.From
+	payload := commands.StartPayload(u.Message.Text)
 
-	// Authenticate (creates account if new)
-	if _, err := Authenticate(ctx, b, user, deps, lg); err != nil {
-		sendError(ctx, b, u.Message.Chat.ID, user.LanguageCode, "auth_error")
+	// Authenticate (creates account if new, crediting the inviter of a referral link)
+	if _, err := authenticateInvited(ctx, b, user, referralCode(payload), deps, lg); err != nil {
+		sendAuthError(ctx, b, u.Message.Chat.ID, user.LanguageCode, err)
 		return
 	}
 
 	// Get saved language (empty for first-time users)
 	savedLang := deps.Sessions.GetLang(user.ID)
 
-	// First-time users: only show language selection
+	// First-time users: only show language selection, the payload waits for it
 	if savedLang == "" {
+		savePayload(user.ID, payload, deps, lg)
 		sendLanguageSelection(ctx, b, u.Message.Chat.ID, user.LanguageCode)
 		lg.Infof("Start command handled (first-time user)")
 		return
@@ -40,14 +43,35 @@ func HandleStart(ctx context.Context, b *bot.Bot, u *models.Update, deps command
 			lg.Warnf("Channel check failed: %v", err)
 			// Fail open - show welcome
 		} else if !isMember {
-			// Not a member - send join prompt
+			// Not a member - send join prompt, the payload waits for the next /start
+			savePayload(user.ID, payload, deps, lg)
 			sendJoinChannelPrompt(ctx, b, u.Message.Chat.ID, savedLang, deps)
 			lg.Infof("Start command handled (pending channel join)")
 			return
 		}
 	}
 
+	// Deep link (or one saved before setup finished) instead of the welcome message
+	if payload != "" && deps.DeepLinks.Dispatch(ctx, b, u, deps, payload) {
+		lg.Infof("Start command handled (deep link)")
+		return
+	}
+	if payload == "" && deps.DeepLinks.Resume(ctx, b, u, deps) {
+		lg.Infof("Start command handled (saved deep link)")
+		return
+	}
+
 	// Member (or no channel required) - show welcome message
 	sendWelcomeMessage(ctx, b, u.Message.Chat.ID, savedLang, deps, lg)
 	lg.Infof("Start command handled")
 }
+
+// savePayload keeps a deep-link payload until the user's setup is finished.
+func savePayload(userID int64, payload string, deps commands.Deps, lg logger.TgLogger) {
+	if payload == "" {
+		return
+	}
+	if err := deps.DeepLinks.Save(userID, payload); err != nil {
+		lg.Errorf("Save deep link failed: %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/botapp/commands"
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// ReferralCallbackPrefix is the callback data prefix for the referral screens.
+const ReferralCallbackPrefix = "ref:"
+
+// referralPrefix marks a referral code in a /start payload, e.g. "/start ref_ABC123".
+const referralPrefix = "ref_"
+
+// referralCodePattern matches codes that fit in a deep-link payload.
+var referralCodePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,60}$`)
+
+// HandleInvite handles the /invite command by showing the user's referral link.
+// Note: Authentication is handled by middleware.
+func HandleInvite(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.Message == nil {
+		return
+	}
+
+	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		info, err := deps.API.GetUserInfo(ctx, token)
+		if err != nil {
+			return err
+		}
+
+		text, keyboard := inviteView(info, lang, deps)
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:      u.Message.Chat.ID,
+			Text:        text,
+			ParseMode:   models.ParseModeHTML,
+			ReplyMarkup: keyboard,
+		})
+		return nil
+	})
+}
+
+// HandleReferralCallback handles the referral screens.
+// Callback data: "ref:invite", "ref:stats", "ref:withdraw".
+func HandleReferralCallback(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.CallbackQuery == nil {
+		return
+	}
+	cb := u.CallbackQuery
+	lang := GetLanguage(ctx, cb.From.ID, cb.From.LanguageCode, deps)
+	action := strings.TrimPrefix(cb.Data, ReferralCallbackPrefix)
+
+	answerCallback(ctx, b, cb.ID, "", false)
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		switch action {
+		case "withdraw":
+			if err := withdrawCommission(ctx, b, cb.From.ID, token, lang, deps); err != nil {
+				return err
+			}
+			fallthrough
+
+		case "stats":
+			info, err := deps.API.GetUserInfo(ctx, token)
+			if err != nil {
+				return err
+			}
+			summary, err := deps.API.GetAffiliateSummary(ctx, token)
+			if err != nil {
+				return err
+			}
+			text, keyboard := referralStatsView(info, summary, lang)
+			editView(ctx, b, cb, text, keyboard)
+
+		default:
+			info, err := deps.API.GetUserInfo(ctx, token)
+			if err != nil {
+				return err
+			}
+			text, keyboard := inviteView(info, lang, deps)
+			editView(ctx, b, cb, text, keyboard)
+		}
+		return nil
+	})
+}
+
+// withdrawCommission moves the whole available commission to the account balance.
+func withdrawCommission(ctx context.Context, b *bot.Bot, userID int64, token, lang string, deps commands.Deps) error {
+	info, err := deps.API.GetUserInfo(ctx, token)
+	if err != nil {
+		return err
+	}
+	if info.Commission <= 0 {
+		SendError(ctx, b, userID, lang, "invite_commission_not_enough")
+		return nil
+	}
+
+	err = deps.API.WithdrawCommission(ctx, token, info.Commission)
+	if api.ErrorCode(err) == api.UserCommissionNotEnough {
+		SendError(ctx, b, userID, lang, "invite_commission_not_enough")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	logger.ForUser(userID).Infof("Commission %s moved to balance", i18n.FormatAmount(info.Commission))
+
+	_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: userID,
+		Text: i18n.TWithData(i18n.Localizer(lang), "invite_withdraw_done", map[string]any{
+			"Amount": i18n.FormatAmount(info.Commission),
+		}),
+		ParseMode: models.ParseModeHTML,
+	})
+	return nil
+}
+
+// authenticateInvited authenticates a user who may have opened a referral link.
+// If the backend rejects the code, the user is told and registered without it.
+func authenticateInvited(ctx context.Context, b *bot.Bot, user *models.User, inviteCode string, deps commands.Deps, lg logger.TgLogger) (string, error) {
+	// Referral codes only count when the account is created
+	if inviteCode == "" || deps.Sessions.GetToken(user.ID) != "" {
+		return Authenticate(ctx, user, deps)
+	}
+
+	token, err := authenticate(ctx, user, inviteCode, deps)
+	if api.ErrorCode(err) != api.InviteCodeError {
+		return token, err
+	}
+
+	lg.Warnf("Invite code %q rejected", inviteCode)
+	sendError(ctx, b, user.ID, user.LanguageCode, "invite_code_invalid")
+	return Authenticate(ctx, user, deps)
+}
+
+// referralCode extracts the referral code from a "ref_<code>" deep-link payload.
+func referralCode(payload string) string {
+	code, ok := strings.CutPrefix(payload, referralPrefix)
+	if !ok || !referralCodePattern.MatchString(code) {
+		return ""
+	}
+	return code
+}
+
+// referralLink returns the deep link that registers users with the referral code.
+func referralLink(username, code string) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s%s", username, referralPrefix, code)
+}
+
+func inviteView(info *api.UserInfo, lang string, deps commands.Deps) (string, *models.InlineKeyboardMarkup) {
+	loc := i18n.Localizer(lang)
+
+	if info.ReferCode == "" || deps.BotUsername == "" {
+		return i18n.T(loc, "invite_unavailable"), nil
+	}
+
+	link := referralLink(deps.BotUsername, info.ReferCode)
+	botName := deps.BotNames[lang]
+	if botName == "" {
+		botName = deps.BotNames["en"]
+	}
+	shareURL := "https://t.me/share/url?url=" + url.QueryEscape(link) +
+		"&text=" + url.QueryEscape(i18n.TWithData(loc, "invite_share_text", map[string]any{"BotName": botName}))
+
+	text := i18n.TWithData(loc, "invite_link", map[string]any{"Link": html.EscapeString(link)})
+	keyboard := &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{Text: i18n.T(loc, "invite_share_button"), URL: shareURL}},
+			{{Text: i18n.T(loc, "invite_stats_button"), CallbackData: ReferralCallbackPrefix + "stats"}},
+		},
+	}
+	return text, keyboard
+}
+
+func referralStatsView(info *api.UserInfo, summary *api.AffiliateSummary, lang string) (string, *models.InlineKeyboardMarkup) {
+	loc := i18n.Localizer(lang)
+
+	text := i18n.TWithData(loc, "invite_stats", map[string]any{
+		"Invited":   summary.Registers,
+		"Earned":    i18n.FormatAmount(summary.TotalCommission),
+		"Available": i18n.FormatAmount(info.Commission),
+	})
+
+	var rows [][]models.InlineKeyboardButton
+	if info.Commission > 0 {
+		rows = append(rows, []models.InlineKeyboardButton{{
+			Text:         i18n.TWithData(loc, "invite_withdraw_button", map[string]any{"Amount": i18n.FormatAmount(info.Commission)}),
+			CallbackData: ReferralCallbackPrefix + "withdraw",
+		}})
+	}
+	rows = append(rows, []models.InlineKeyboardButton{
+		{Text: i18n.T(loc, "buy_back_button"), CallbackData: ReferralCallbackPrefix + "invite"},
+	})
+
+	return text, &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
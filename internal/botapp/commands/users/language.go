@@ -78,8 +78,10 @@ func HandleLanguageCallback(ctx context.Context, b *bot.Bot, u *models.Update, d
 		}
 	}
 
-	// Member (or no channel required) - send welcome message
+	// Member (or no channel required) - send welcome message,
+	// then continue with the deep link the user started with (if any)
 	sendWelcomeMessage(ctx, b, cb.From.ID, lang, deps, lg)
+	deps.DeepLinks.Resume(ctx, b, u, deps)
 	lg.Infof("Language changed to %s", lang)
 }
 
@@ -0,0 +1,108 @@
+package users
+
+import (
+	"context"
+	"strings"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/botapp/commands"
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// NotificationsCallbackPrefix is the callback data prefix for notification toggles.
+const NotificationsCallbackPrefix = "notify:"
+
+// notificationToggles lists the settings in display order.
+var notificationToggles = []struct {
+	Name  string // Callback value
+	Label string // Translation key
+	Field func(*api.NotifySettings) *bool
+}{
+	{"orders", "notifications_orders", func(s *api.NotifySettings) *bool { return &s.OrderUpdates }},
+	{"expiry", "notifications_expiry", func(s *api.NotifySettings) *bool { return &s.ExpiryReminders }},
+	{"traffic", "notifications_traffic", func(s *api.NotifySettings) *bool { return &s.TrafficAlerts }},
+	{"announcements", "notifications_announcements", func(s *api.NotifySettings) *bool { return &s.Announcements }},
+}
+
+// HandleNotifications shows the notification settings with a toggle per category.
+// Note: Authentication is handled by middleware.
+func HandleNotifications(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.Message == nil {
+		return
+	}
+
+	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		settings, err := deps.API.GetNotifySettings(ctx, token)
+		if err != nil {
+			return err
+		}
+
+		text, keyboard := notificationsView(settings, lang)
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:      u.Message.Chat.ID,
+			Text:        text,
+			ParseMode:   models.ParseModeHTML,
+			ReplyMarkup: keyboard,
+		})
+		return nil
+	})
+}
+
+// HandleNotificationsCallback toggles a setting: "notify:<orders|expiry|traffic|announcements>".
+func HandleNotificationsCallback(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.CallbackQuery == nil {
+		return
+	}
+	cb := u.CallbackQuery
+	lang := GetLanguage(ctx, cb.From.ID, cb.From.LanguageCode, deps)
+	name := strings.TrimPrefix(cb.Data, NotificationsCallbackPrefix)
+
+	answerCallback(ctx, b, cb.ID, "", false)
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		settings, err := deps.API.GetNotifySettings(ctx, token)
+		if err != nil {
+			return err
+		}
+
+		for _, t := range notificationToggles {
+			if t.Name == name {
+				field := t.Field(settings)
+				*field = !*field
+			}
+		}
+
+		if err := deps.API.UpdateNotifySettings(ctx, token, *settings); err != nil {
+			return err
+		}
+		deps.Preferences.Remember(cb.From.ID, *settings)
+		logger.ForUser(cb.From.ID).Infof("Notification settings updated (%s)", name)
+
+		text, keyboard := notificationsView(settings, lang)
+		editView(ctx, b, cb, text, keyboard)
+		return nil
+	})
+}
+
+func notificationsView(settings *api.NotifySettings, lang string) (string, *models.InlineKeyboardMarkup) {
+	loc := i18n.Localizer(lang)
+
+	var rows [][]models.InlineKeyboardButton
+	for _, t := range notificationToggles {
+		mark := "❌"
+		if *t.Field(settings) {
+			mark = "✅"
+		}
+		rows = append(rows, []models.InlineKeyboardButton{{
+			Text:         mark + " " + i18n.T(loc, t.Label),
+			CallbackData: NotificationsCallbackPrefix + t.Name,
+		}})
+	}
+
+	return i18n.T(loc, "notifications_title"), &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
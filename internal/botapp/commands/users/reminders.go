@@ -0,0 +1,94 @@
+package users
+
+import (
+	"context"
+	"strings"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/botapp/commands"
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// RemindersCallbackPrefix is the callback data prefix for reminder toggles.
+const RemindersCallbackPrefix = "reminders:"
+
+// HandleReminders shows whether reminders are enabled with a toggle button.
+// Reminders are the expiry and traffic toggles of /notifications.
+// Note: Authentication is handled by middleware.
+func HandleReminders(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.Message == nil {
+		return
+	}
+
+	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		settings, err := deps.API.GetNotifySettings(ctx, token)
+		if err != nil {
+			return err
+		}
+
+		text, keyboard := remindersView(remindersEnabled(settings), lang)
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:      u.Message.Chat.ID,
+			Text:        text,
+			ReplyMarkup: keyboard,
+		})
+		return nil
+	})
+}
+
+// HandleRemindersCallback handles "reminders:on" / "reminders:off" toggles,
+// which switch expiry and traffic notifications together.
+func HandleRemindersCallback(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.CallbackQuery == nil {
+		return
+	}
+	cb := u.CallbackQuery
+	lang := GetLanguage(ctx, cb.From.ID, cb.From.LanguageCode, deps)
+	enabled := strings.TrimPrefix(cb.Data, RemindersCallbackPrefix) == "on"
+
+	answerCallback(ctx, b, cb.ID, "", false)
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		settings, err := deps.API.GetNotifySettings(ctx, token)
+		if err != nil {
+			return err
+		}
+
+		settings.ExpiryReminders = enabled
+		settings.TrafficAlerts = enabled
+		if err := deps.API.UpdateNotifySettings(ctx, token, *settings); err != nil {
+			return err
+		}
+		deps.Preferences.Remember(cb.From.ID, *settings)
+		logger.ForUser(cb.From.ID).Infof("Reminders enabled: %v", enabled)
+
+		text, keyboard := remindersView(enabled, lang)
+		editView(ctx, b, cb, text, keyboard)
+		return nil
+	})
+}
+
+// remindersEnabled returns true if any reminder category is on.
+func remindersEnabled(settings *api.NotifySettings) bool {
+	return settings.ExpiryReminders || settings.TrafficAlerts
+}
+
+func remindersView(enabled bool, lang string) (string, *models.InlineKeyboardMarkup) {
+	loc := i18n.Localizer(lang)
+
+	text := i18n.T(loc, "reminders_off")
+	button := models.InlineKeyboardButton{Text: i18n.T(loc, "reminders_enable_button"), CallbackData: RemindersCallbackPrefix + "on"}
+	if enabled {
+		text = i18n.T(loc, "reminders_on")
+		button = models.InlineKeyboardButton{Text: i18n.T(loc, "reminders_disable_button"), CallbackData: RemindersCallbackPrefix + "off"}
+	}
+
+	return text, &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{{button}},
+	}
+}
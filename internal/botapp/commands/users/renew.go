@@ -0,0 +1,218 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/botapp/commands"
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/archnets/telegram-bot/internal/notify"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// SubscriptionCallbackPrefix is the callback data prefix for subscription actions.
+const SubscriptionCallbackPrefix = "sub:"
+
+// Subscription actions offered under /traffic.
+const (
+	subActionRenew = "renew"
+	subActionReset = "reset"
+)
+
+// HandleSubscriptionCallback handles renewal and traffic reset of a subscription.
+// Callback data: "sub:<renew|reset>:<id>" shows the price confirmation,
+// "sub:<renew|reset>:<id>:<method>" places the order, "sub:cancel" dismisses it.
+func HandleSubscriptionCallback(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.CallbackQuery == nil {
+		return
+	}
+	cb := u.CallbackQuery
+	lang := GetLanguage(ctx, cb.From.ID, cb.From.LanguageCode, deps)
+
+	parts := strings.Split(strings.TrimPrefix(cb.Data, SubscriptionCallbackPrefix), ":")
+	answerCallback(ctx, b, cb.ID, "", false)
+
+	action := parts[0]
+	if action == "cancel" || len(parts) < 2 || (action != subActionRenew && action != subActionReset) {
+		deleteMessage(ctx, b, cb)
+		return
+	}
+	subID, _ := strconv.ParseInt(parts[1], 10, 64)
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		sub, err := findUserSubscription(ctx, token, subID, deps)
+		if err != nil {
+			return err
+		}
+
+		// Confirmation step with the exact price
+		if len(parts) < 3 {
+			text, keyboard, err := subscriptionConfirm(ctx, token, action, sub, lang, deps)
+			if err != nil {
+				return handlePurchaseError(ctx, b, cb.From.ID, lang, err)
+			}
+			_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID:      cb.From.ID,
+				Text:        text,
+				ParseMode:   models.ParseModeHTML,
+				ReplyMarkup: keyboard,
+			})
+			return nil
+		}
+
+		methodID, _ := strconv.ParseInt(parts[2], 10, 64)
+		return placeSubscriptionOrder(ctx, b, cb, token, action, sub, methodID, lang, deps)
+	})
+}
+
+// placeSubscriptionOrder creates a renewal or reset order and hands off to checkout.
+func placeSubscriptionOrder(ctx context.Context, b *bot.Bot, cb *models.CallbackQuery, token, action string, sub *api.UserSubscription, methodID int64, lang string, deps commands.Deps) error {
+	// The confirmation can't be used twice
+	release, ok := claimButtons(ctx, b, cb)
+	if !ok {
+		return nil // Another press is placing the order
+	}
+
+	req := api.OrderRequest{UserSubscribeID: sub.ID, Payment: methodID}
+
+	var orderNo string
+	var err error
+	if action == subActionRenew {
+		req.Quantity = 1
+		orderNo, err = deps.API.Renew(ctx, token, req)
+	} else {
+		orderNo, err = deps.API.ResetTraffic(ctx, token, req)
+	}
+	if err != nil {
+		if rejected(err) {
+			release()
+		}
+		return handlePurchaseError(ctx, b, cb.From.ID, lang, err)
+	}
+	logger.ForUser(cb.From.ID).Infof("Order %s placed (%s subscription %d)", orderNo, action, sub.ID)
+
+	method := findPaymentMethod(ctx, token, methodID, deps)
+	title := i18n.TWithData(i18n.Localizer(lang), "stars_title_"+action, map[string]any{"Name": subscriptionName(sub)})
+	if err := sendCheckout(ctx, b, cb.From.ID, token, orderNo, method, title, lang, deps); err != nil {
+		return err
+	}
+
+	deps.Orders.Watch(notify.WatchRequest{
+		TelegramID:    cb.From.ID,
+		Token:         token,
+		OrderNo:       orderNo,
+		SubscribeID:   sub.SubscribeID,
+		SubscribeName: subscriptionName(sub),
+		PaymentMethod: paymentName(method, lang),
+	})
+	return nil
+}
+
+// subscriptionConfirm shows the price of a renewal or reset with a button per payment method.
+func subscriptionConfirm(ctx context.Context, token, action string, sub *api.UserSubscription, lang string, deps commands.Deps) (string, *models.InlineKeyboardMarkup, error) {
+	loc := i18n.Localizer(lang)
+
+	price := sub.Subscribe.Replacement
+	messageID := "reset_confirm"
+	if action == subActionRenew {
+		price = renewalPrice(sub.Subscribe)
+		messageID = "renew_confirm"
+	}
+
+	info, err := deps.API.GetUserInfo(ctx, token)
+	if err != nil {
+		return "", nil, err
+	}
+	methods, err := paymentMethods(ctx, token, deps)
+	if err != nil {
+		return "", nil, err
+	}
+
+	text := i18n.TWithData(loc, messageID, map[string]any{
+		"Name":    html.EscapeString(subscriptionName(sub)),
+		"Period":  periodLabel(1, sub.Subscribe.UnitTime, lang),
+		"Price":   i18n.FormatAmount(price),
+		"Balance": i18n.FormatAmount(info.Balance),
+	})
+
+	var rows [][]models.InlineKeyboardButton
+	for _, m := range methods {
+		label := "💳 " + paymentName(m, lang)
+		if m.Platform == api.PaymentPlatformBalance {
+			label = i18n.TWithData(loc, "pay_from_balance_button", map[string]any{"Price": i18n.FormatAmount(price)})
+		}
+		rows = append(rows, []models.InlineKeyboardButton{{
+			Text:         label,
+			CallbackData: fmt.Sprintf("%s%s:%d:%d", SubscriptionCallbackPrefix, action, sub.ID, m.ID),
+		}})
+	}
+	rows = append(rows, []models.InlineKeyboardButton{
+		{Text: i18n.T(loc, "buy_cancel_button"), CallbackData: SubscriptionCallbackPrefix + "cancel"},
+	})
+
+	return text, &models.InlineKeyboardMarkup{InlineKeyboard: rows}, nil
+}
+
+// renewalPrice returns the price of renewing a subscription of the plan for
+// one period, with the plan's single-period discount if it has one. The order
+// preview quotes new purchases, which the backend may refuse where it would
+// accept a renewal (a plan no longer sold, single subscription mode).
+func renewalPrice(plan api.Subscribe) int64 {
+	price := plan.UnitPrice
+	for _, d := range plan.Discount {
+		if d.Quantity == 1 && d.Discount > 0 {
+			price = price * d.Discount / 100
+		}
+	}
+	return price
+}
+
+// subscriptionActions returns renew/reset buttons for each subscription.
+func subscriptionActions(subs []api.UserSubscription, lang string) *models.InlineKeyboardMarkup {
+	loc := i18n.Localizer(lang)
+
+	var rows [][]models.InlineKeyboardButton
+	for _, sub := range subs {
+		name := truncateLabel(subscriptionName(&sub), maxButtonLabel)
+		row := []models.InlineKeyboardButton{{
+			Text:         i18n.TWithData(loc, "renew_button", map[string]any{"Name": name}),
+			CallbackData: fmt.Sprintf("%s%s:%d", SubscriptionCallbackPrefix, subActionRenew, sub.ID),
+		}}
+		if sub.Traffic > 0 {
+			row = append(row, models.InlineKeyboardButton{
+				Text:         i18n.TWithData(loc, "reset_button", map[string]any{"Name": name}),
+				CallbackData: fmt.Sprintf("%s%s:%d", SubscriptionCallbackPrefix, subActionReset, sub.ID),
+			})
+		}
+		rows = append(rows, row)
+	}
+
+	return &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+func findUserSubscription(ctx context.Context, token string, id int64, deps commands.Deps) (*api.UserSubscription, error) {
+	subs, err := deps.API.GetUserSubscriptions(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range subs {
+		if sub.ID == id {
+			return &sub, nil
+		}
+	}
+	return nil, &api.Error{Code: api.SubscribeNotAvailable, Message: "subscription not found"}
+}
+
+// subscriptionName returns the custom name of a subscription, or its plan name.
+func subscriptionName(sub *api.UserSubscription) string {
+	if sub.CustomName != "" {
+		return sub.CustomName
+	}
+	return sub.Subscribe.Name
+}
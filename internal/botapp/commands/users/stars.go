@@ -0,0 +1,132 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"html"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/botapp/commands"
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/archnets/telegram-bot/internal/stars"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// The checkout errors are explained like backend errors.
+func init() {
+	commands.RegisterErrorKey(stars.ErrNotPayable, "stars_order_not_payable")
+	commands.RegisterErrorKey(stars.ErrAmountMismatch, "stars_price_changed")
+}
+
+// maxInvoiceTitle is the maximum length of an invoice title.
+const maxInvoiceTitle = 32
+
+// HandlePreCheckoutQuery approves a Stars payment if its order is still payable at that price.
+// Telegram requires an answer within 10 seconds.
+func HandlePreCheckoutQuery(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	q := u.PreCheckoutQuery
+	if q == nil || q.From == nil {
+		return
+	}
+	lg := logger.ForUser(q.From.ID)
+	lang := GetLanguage(ctx, q.From.ID, q.From.LanguageCode, deps)
+
+	err := validatePreCheckout(ctx, q, deps)
+
+	answer := &bot.AnswerPreCheckoutQueryParams{PreCheckoutQueryID: q.ID, OK: err == nil}
+	if err != nil {
+		lg.Warnf("Pre-checkout of order %s rejected: %v", q.InvoicePayload, err)
+
+		key, data := "stars_checkout_failed", map[string]any(nil)
+		if errors.Is(err, stars.ErrNotPayable) || errors.Is(err, stars.ErrAmountMismatch) || api.IsUnavailable(err) || api.ErrorCode(err) != 0 {
+			key, data = commands.APIErrorMessage(err)
+		}
+		answer.ErrorMessage = i18n.TWithData(i18n.Localizer(lang), key, data)
+	}
+
+	if _, err := b.AnswerPreCheckoutQuery(ctx, answer); err != nil {
+		lg.Errorf("Answer pre-checkout query failed: %v", err)
+	}
+}
+
+// validatePreCheckout validates the query with the user's token.
+// An expired token is refreshed by the API client.
+func validatePreCheckout(ctx context.Context, q *models.PreCheckoutQuery, deps commands.Deps) error {
+	if !deps.Stars.Enabled() {
+		return errors.New("stars payments disabled")
+	}
+
+	token, err := deps.Tokens.Token(ctx, q.From.ID)
+	if err != nil {
+		return err
+	}
+	return deps.Stars.Validate(ctx, token, q)
+}
+
+// HandleSuccessfulPayment records a received Stars payment and finalizes its order.
+// The user has already been charged, so the payment is recorded before
+// anything that depends on the backend; no authentication is required.
+func HandleSuccessfulPayment(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.Message == nil || u.Message.SuccessfulPayment == nil || u.Message.From == nil {
+		return
+	}
+	sp := u.Message.SuccessfulPayment
+	lg := logger.ForUser(u.Message.From.ID)
+
+	lg.Infof("Stars payment %s received for order %s (%d XTR)", sp.TelegramPaymentChargeID, sp.InvoicePayload, sp.TotalAmount)
+
+	messageID := "stars_payment_received"
+	alert := stars.AlertData(stars.Payment{
+		ChargeID:   sp.TelegramPaymentChargeID,
+		TelegramID: u.Message.From.ID,
+		OrderNo:    sp.InvoicePayload,
+		Stars:      sp.TotalAmount,
+	})
+	err := deps.Stars.Complete(ctx, u.Message.From.ID, sp)
+	switch {
+	case err == nil:
+	case errors.Is(err, stars.ErrNotRecorded):
+		// Nothing retries a payment that wasn't saved
+		lg.Errorf("Stars payment %s lost: %v", sp.TelegramPaymentChargeID, err)
+		deps.Notifier.SendToAdmins(ctx, "admin_stars_payment_unrecorded", alert)
+		messageID = "stars_payment_unrecorded"
+	case errors.Is(err, stars.ErrPaymentHeld):
+		lg.Warnf("Stars payment %s held: %v", sp.TelegramPaymentChargeID, err)
+		deps.Notifier.SendToAdmins(ctx, "admin_stars_payment_held", alert)
+		messageID = "stars_payment_held"
+	default:
+		// Recorded payments are finalized later by the retry loop
+		lg.Errorf("Finalize Stars payment %s failed: %v", sp.TelegramPaymentChargeID, err)
+		messageID = "stars_payment_delayed"
+	}
+
+	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
+	loc := i18n.Localizer(lang)
+	data := map[string]any{
+		"OrderNo":  html.EscapeString(sp.InvoicePayload),
+		"ChargeID": html.EscapeString(sp.TelegramPaymentChargeID),
+	}
+
+	_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    u.Message.Chat.ID,
+		Text:      i18n.TWithData(loc, messageID, data),
+		ParseMode: models.ParseModeHTML,
+	})
+}
+
+// sendStarsInvoice sends a Stars invoice for an order in place of a checkout link.
+func sendStarsInvoice(ctx context.Context, b *bot.Bot, chatID int64, token, orderNo, title, lang string, deps commands.Deps) error {
+	if !deps.Stars.Enabled() {
+		SendError(ctx, b, chatID, lang, "buy_payment_not_found")
+		return nil
+	}
+
+	return deps.Stars.SendInvoice(ctx, b, token, stars.Invoice{
+		ChatID:      chatID,
+		OrderNo:     orderNo,
+		Title:       truncateLabel(title, maxInvoiceTitle),
+		Description: i18n.TWithData(i18n.Localizer(lang), "stars_invoice_description", map[string]any{"OrderNo": orderNo}),
+	})
+}
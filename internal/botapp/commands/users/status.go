@@ -2,27 +2,66 @@ package users
 
 import (
 	"context"
+	"html"
+	"time"
 
 	"github.com/archnets/telegram-bot/internal/botapp/commands"
+	"github.com/archnets/telegram-bot/internal/core"
 	"github.com/archnets/telegram-bot/internal/i18n"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 )
 
 // HandleStatus handles the /status command.
+// Shows the user's account summary and nearest subscription expiry.
 func HandleStatus(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
 	if u.Message == nil {
 		return
 	}
 
-	// Use saved language if available, otherwise Telegram's
-	// Use saved language if available, otherwise Telegram's
 	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		status, err := deps.Subscription.GetStatus(ctx, token)
+		if err != nil {
+			return err
+		}
+
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    u.Message.Chat.ID,
+			Text:      formatStatusMessage(status, lang),
+			ParseMode: models.ParseModeHTML,
+		})
+		return nil
+	})
+}
+
+func formatStatusMessage(status *core.AccountStatus, lang string) string {
 	loc := i18n.Localizer(lang)
 
-	// TODO: Implement actual status logic
-	_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID: u.Message.Chat.ID,
-		Text:   i18n.T(loc, "status_unavailable"),
+	email := status.Email
+	if email == "" {
+		email = i18n.T(loc, "status_not_set")
+	}
+	referCode := status.ReferCode
+	if referCode == "" {
+		referCode = i18n.T(loc, "status_not_set")
+	}
+
+	msg := i18n.TWithData(loc, "status_summary", map[string]any{
+		"Email":     html.EscapeString(email),
+		"Balance":   i18n.FormatAmount(status.Balance),
+		"ReferCode": html.EscapeString(referCode),
+		"Active":    status.ActiveSubscriptions,
+	})
+
+	daysLeft := status.DaysLeft(time.Now())
+	if daysLeft < 0 {
+		return msg + "\n" + i18n.T(loc, "status_no_expiry")
+	}
+
+	return msg + "\n" + i18n.TWithData(loc, "status_expiry", map[string]any{
+		"ExpireDate": status.NearestExpiry.Format("2006-01-02"),
+		"DaysLeft":   daysLeft,
 	})
 }
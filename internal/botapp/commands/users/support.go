@@ -0,0 +1,367 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/botapp/commands"
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// SupportCallbackPrefix is the callback data prefix for ticket actions.
+const SupportCallbackPrefix = "ticket:"
+
+// Conversation states of the support flow.
+const (
+	StateTicketTitle   = "user.ticket.title"
+	StateTicketMessage = "user.ticket.message"
+	StateTicketReply   = "user.ticket.reply"
+)
+
+const (
+	ticketPageSize = 5
+
+	// maxThreadLength keeps the thread view below Telegram's 4096 character limit.
+	maxThreadLength = 3500
+
+	// maxTicketTitle is the maximum ticket title length in characters.
+	maxTicketTitle = 100
+)
+
+// HandleSupport handles the /support command by listing the user's tickets.
+// Note: Authentication is handled by middleware.
+func HandleSupport(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.Message == nil {
+		return
+	}
+
+	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		tickets, err := deps.API.GetTickets(ctx, token, 1, ticketPageSize)
+		if err != nil {
+			return err
+		}
+
+		text, keyboard := ticketListView(tickets, 1, lang)
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:      u.Message.Chat.ID,
+			Text:        text,
+			ParseMode:   models.ParseModeHTML,
+			ReplyMarkup: keyboard,
+		})
+		return nil
+	})
+}
+
+// HandleSupportCallback handles ticket actions.
+// Callback data: "ticket:list:<page>", "ticket:new", "ticket:view:<id>",
+// "ticket:reply:<id>", "ticket:close:<id>".
+func HandleSupportCallback(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.CallbackQuery == nil {
+		return
+	}
+	cb := u.CallbackQuery
+	lg := logger.ForUser(cb.From.ID)
+	lang := GetLanguage(ctx, cb.From.ID, cb.From.LanguageCode, deps)
+	loc := i18n.Localizer(lang)
+
+	action, arg, _ := strings.Cut(strings.TrimPrefix(cb.Data, SupportCallbackPrefix), ":")
+	id, _ := strconv.ParseInt(arg, 10, 64)
+
+	switch action {
+	case "list":
+		answerCallback(ctx, b, cb.ID, "", false)
+		page := max(int(id), 1)
+		ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+			tickets, err := deps.API.GetTickets(ctx, token, page, ticketPageSize)
+			if err != nil {
+				return err
+			}
+			text, keyboard := ticketListView(tickets, page, lang)
+			editView(ctx, b, cb, text, keyboard)
+			return nil
+		})
+
+	case "new":
+		answerCallback(ctx, b, cb.ID, "", false)
+		startConversation(ctx, b, cb.From.ID, StateTicketTitle, nil, i18n.T(loc, "ticket_ask_title"), deps)
+
+	case "view":
+		answerCallback(ctx, b, cb.ID, "", false)
+		ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+			ticket, err := deps.API.GetTicket(ctx, token, id)
+			if err != nil {
+				return err
+			}
+			text, keyboard := ticketView(ticket, lang)
+			editView(ctx, b, cb, text, keyboard)
+			return nil
+		})
+
+	case "reply":
+		answerCallback(ctx, b, cb.ID, "", false)
+		data := map[string]string{"ticket_id": arg}
+		startConversation(ctx, b, cb.From.ID, StateTicketReply, data, i18n.T(loc, "ticket_ask_reply"), deps)
+
+	case "close":
+		answerCallback(ctx, b, cb.ID, "", false)
+		ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+			if err := deps.API.CloseTicket(ctx, token, id); err != nil {
+				return err
+			}
+			lg.Infof("Ticket %d closed", id)
+
+			// The refreshed view shows the closed status
+			ticket, err := deps.API.GetTicket(ctx, token, id)
+			if err != nil {
+				return err
+			}
+			text, keyboard := ticketView(ticket, lang)
+			editView(ctx, b, cb, text, keyboard)
+			return nil
+		})
+
+	default:
+		answerCallback(ctx, b, cb.ID, "", false)
+	}
+}
+
+// HandleTicketTitle receives the subject of a new ticket.
+func HandleTicketTitle(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps, conv *commands.Conversation) {
+	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
+	loc := i18n.Localizer(lang)
+
+	title := strings.TrimSpace(u.Message.Text)
+	if title == "" || utf8.RuneCountInString(title) > maxTicketTitle {
+		SendError(ctx, b, u.Message.Chat.ID, lang, "ticket_ask_title")
+		return
+	}
+
+	data := map[string]string{"title": title}
+	startConversation(ctx, b, u.Message.From.ID, StateTicketMessage, data, i18n.T(loc, "ticket_ask_message"), deps)
+}
+
+// HandleTicketMessage receives the description of a new ticket and opens it.
+func HandleTicketMessage(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps, conv *commands.Conversation) {
+	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
+
+	description := strings.TrimSpace(u.Message.Text)
+	if description == "" {
+		SendError(ctx, b, u.Message.Chat.ID, lang, "ticket_ask_message")
+		return
+	}
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		if err := deps.API.CreateTicket(ctx, token, conv.Data["title"], description); err != nil {
+			return err
+		}
+		deps.Conversations.End(u.Message.From.ID)
+
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:      u.Message.Chat.ID,
+			Text:        i18n.T(i18n.Localizer(lang), "ticket_created"),
+			ReplyMarkup: ticketBackKeyboard(lang),
+		})
+		logger.ForUpdate(u).Infof("Ticket created")
+		return nil
+	})
+}
+
+// HandleTicketReply receives a user's reply to an existing ticket.
+func HandleTicketReply(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps, conv *commands.Conversation) {
+	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
+
+	content := strings.TrimSpace(u.Message.Text)
+	if content == "" {
+		SendError(ctx, b, u.Message.Chat.ID, lang, "ticket_ask_reply")
+		return
+	}
+
+	id, err := strconv.ParseInt(conv.Data["ticket_id"], 10, 64)
+	if err != nil {
+		deps.Conversations.End(u.Message.From.ID)
+		return
+	}
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		if err := deps.API.ReplyTicket(ctx, token, id, content); err != nil {
+			return err
+		}
+		deps.Conversations.End(u.Message.From.ID)
+
+		loc := i18n.Localizer(lang)
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: u.Message.Chat.ID,
+			Text:   i18n.T(loc, "ticket_reply_sent"),
+			ReplyMarkup: &models.InlineKeyboardMarkup{
+				InlineKeyboard: [][]models.InlineKeyboardButton{{
+					{Text: i18n.T(loc, "ticket_view_button"), CallbackData: fmt.Sprintf("%sview:%d", SupportCallbackPrefix, id)},
+				}},
+			},
+		})
+		logger.ForUpdate(u).Infof("Replied to ticket %d", id)
+		return nil
+	})
+}
+
+// startConversation puts the user into a state and sends the prompt for it to their private chat.
+func startConversation(ctx context.Context, b *bot.Bot, userID int64, state string, data map[string]string, prompt string, deps commands.Deps) {
+	if err := deps.Conversations.Enter(userID, state, data); err != nil {
+		logger.ForUser(userID).Errorf("Enter %s failed: %v", state, err)
+		return
+	}
+
+	_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: userID,
+		Text:   prompt,
+	})
+}
+
+func ticketListView(tickets *api.TicketListResponse, page int, lang string) (string, *models.InlineKeyboardMarkup) {
+	loc := i18n.Localizer(lang)
+
+	text := i18n.T(loc, "ticket_list_empty")
+	if tickets.Total > 0 {
+		text = i18n.TWithData(loc, "ticket_list_title", map[string]any{"Total": tickets.Total})
+	}
+
+	var rows [][]models.InlineKeyboardButton
+	for _, t := range tickets.List {
+		rows = append(rows, []models.InlineKeyboardButton{{
+			Text:         fmt.Sprintf("%s #%d %s", ticketStatusIcon(t.Status), t.ID, truncateLabel(t.Title, maxButtonLabel)),
+			CallbackData: fmt.Sprintf("%sview:%d", SupportCallbackPrefix, t.ID),
+		}})
+	}
+
+	var nav []models.InlineKeyboardButton
+	if page > 1 {
+		nav = append(nav, models.InlineKeyboardButton{
+			Text:         i18n.T(loc, "ticket_prev_button"),
+			CallbackData: fmt.Sprintf("%slist:%d", SupportCallbackPrefix, page-1),
+		})
+	}
+	if int64(page*ticketPageSize) < tickets.Total {
+		nav = append(nav, models.InlineKeyboardButton{
+			Text:         i18n.T(loc, "ticket_next_button"),
+			CallbackData: fmt.Sprintf("%slist:%d", SupportCallbackPrefix, page+1),
+		})
+	}
+	if len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+
+	rows = append(rows, []models.InlineKeyboardButton{{
+		Text:         i18n.T(loc, "ticket_new_button"),
+		CallbackData: SupportCallbackPrefix + "new",
+	}})
+
+	return text, &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+func ticketView(t *api.Ticket, lang string) (string, *models.InlineKeyboardMarkup) {
+	loc := i18n.Localizer(lang)
+
+	header := i18n.TWithData(loc, "ticket_view_header", map[string]any{
+		"ID":     t.ID,
+		"Title":  html.EscapeString(t.Title),
+		"Status": ticketStatusLabel(t.Status, lang),
+	})
+
+	// The description is the first message of the thread
+	messages := append([]api.TicketFollow{{
+		From:      api.TicketFromUser,
+		Type:      api.TicketFollowText,
+		Content:   t.Description,
+		CreatedAt: t.CreatedAt,
+	}}, t.Follow...)
+
+	// Keep the most recent messages that fit
+	var parts []string
+	length := len(header)
+	for i := len(messages) - 1; i >= 0; i-- {
+		part := formatTicketMessage(messages[i], lang)
+		if length+len(part) > maxThreadLength {
+			parts = append(parts, "…")
+			break
+		}
+		length += len(part)
+		parts = append(parts, part)
+	}
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+
+	text := header + "\n\n" + strings.Join(parts, "\n\n")
+
+	var rows [][]models.InlineKeyboardButton
+	if t.Status != api.TicketStatusClosed {
+		rows = append(rows, []models.InlineKeyboardButton{
+			{Text: i18n.T(loc, "ticket_reply_button"), CallbackData: fmt.Sprintf("%sreply:%d", SupportCallbackPrefix, t.ID)},
+			{Text: i18n.T(loc, "ticket_close_button"), CallbackData: fmt.Sprintf("%sclose:%d", SupportCallbackPrefix, t.ID)},
+		})
+	}
+	rows = append(rows, ticketBackKeyboard(lang).InlineKeyboard...)
+
+	return text, &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+func formatTicketMessage(f api.TicketFollow, lang string) string {
+	loc := i18n.Localizer(lang)
+
+	from := i18n.T(loc, "ticket_from_support")
+	if f.From == api.TicketFromUser {
+		from = i18n.T(loc, "ticket_from_user")
+	}
+
+	content := html.EscapeString(f.Content)
+	if f.Type == api.TicketFollowImage {
+		content = fmt.Sprintf("🖼 <a href=\"%s\">%s</a>", html.EscapeString(f.Content), i18n.T(loc, "ticket_image"))
+	}
+
+	return fmt.Sprintf("<b>%s</b> · %s\n%s", from, time.UnixMilli(f.CreatedAt).Format("2006-01-02 15:04"), content)
+}
+
+func ticketBackKeyboard(lang string) *models.InlineKeyboardMarkup {
+	return &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{{
+			{Text: i18n.T(i18n.Localizer(lang), "ticket_back_button"), CallbackData: SupportCallbackPrefix + "list:1"},
+		}},
+	}
+}
+
+func ticketStatusIcon(status int) string {
+	switch status {
+	case api.TicketStatusPending:
+		return "🕓"
+	case api.TicketStatusWaiting:
+		return "💬"
+	case api.TicketStatusResolved:
+		return "✅"
+	default:
+		return "🔒"
+	}
+}
+
+func ticketStatusLabel(status int, lang string) string {
+	loc := i18n.Localizer(lang)
+	switch status {
+	case api.TicketStatusPending:
+		return i18n.T(loc, "ticket_status_pending")
+	case api.TicketStatusWaiting:
+		return i18n.T(loc, "ticket_status_waiting")
+	case api.TicketStatusResolved:
+		return i18n.T(loc, "ticket_status_resolved")
+	default:
+		return i18n.T(loc, "ticket_status_closed")
+	}
+}
@@ -0,0 +1,190 @@
+package users
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/botapp/commands"
+	"github.com/archnets/telegram-bot/internal/core"
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/archnets/telegram-bot/internal/usage"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// UsageCallbackPrefix is the callback data prefix for usage chart periods.
+const UsageCallbackPrefix = "use:"
+
+// usagePeriods are the chart periods offered, in days; the first is the default.
+var usagePeriods = []int{7, usage.MaxDays}
+
+// usageChartFile is the file name of uploaded usage charts.
+const usageChartFile = "usage.png"
+
+// HandleUsage sends a daily usage chart for each active subscription.
+// Note: Authentication is handled by middleware.
+func HandleUsage(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.Message == nil {
+		return
+	}
+
+	lang := GetLanguage(ctx, u.Message.From.ID, u.Message.From.LanguageCode, deps)
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		subs, err := deps.API.GetUserSubscriptions(ctx, token)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		sent := false
+		for _, sub := range subs {
+			if !core.IsSubscriptionActive(sub, now) {
+				continue
+			}
+			if err := sendUsageChart(ctx, b, u.Message.Chat.ID, u.Message.From.ID, &sub, usagePeriods[0], lang, deps); err != nil {
+				return err
+			}
+			sent = true
+		}
+		if !sent {
+			SendError(ctx, b, u.Message.Chat.ID, lang, "no_subscriptions")
+		}
+		return nil
+	})
+}
+
+// HandleUsageCallback switches a usage chart to another period.
+// Callback data: "use:<subscription_id>:<days>".
+func HandleUsageCallback(ctx context.Context, b *bot.Bot, u *models.Update, deps commands.Deps) {
+	if u.CallbackQuery == nil || u.CallbackQuery.Message.Message == nil {
+		return
+	}
+	cb := u.CallbackQuery
+	msg := cb.Message.Message
+	lang := GetLanguage(ctx, cb.From.ID, cb.From.LanguageCode, deps)
+
+	parts := strings.Split(strings.TrimPrefix(cb.Data, UsageCallbackPrefix), ":")
+	if len(parts) != 2 {
+		answerCallback(ctx, b, cb.ID, "", false)
+		return
+	}
+	subID, _ := strconv.ParseInt(parts[0], 10, 64)
+	days, _ := strconv.Atoi(parts[1])
+
+	answerCallback(ctx, b, cb.ID, "", false)
+
+	ExecuteWithAuth(ctx, b, u, deps, func(token string) error {
+		sub, err := findUserSubscription(ctx, token, subID, deps)
+		if err != nil {
+			return err
+		}
+
+		caption, chart, keyboard, err := usageView(cb.From.ID, sub, days, lang, deps)
+		if err != nil || chart == nil {
+			return err
+		}
+
+		_, err = b.EditMessageMedia(ctx, &bot.EditMessageMediaParams{
+			ChatID:    msg.Chat.ID,
+			MessageID: msg.ID,
+			Media: &models.InputMediaPhoto{
+				Media:           "attach://" + usageChartFile,
+				Caption:         caption,
+				ParseMode:       models.ParseModeHTML,
+				MediaAttachment: bytes.NewReader(chart),
+			},
+			ReplyMarkup: keyboard,
+		})
+		return err
+	})
+}
+
+// sendUsageChart sends a subscription's usage chart, or a notice if nothing has been sampled yet.
+func sendUsageChart(ctx context.Context, b *bot.Bot, chatID, userID int64, sub *api.UserSubscription, days int, lang string, deps commands.Deps) error {
+	caption, chart, keyboard, err := usageView(userID, sub, days, lang, deps)
+	if err != nil {
+		return err
+	}
+
+	if chart == nil {
+		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    chatID,
+			Text:      caption,
+			ParseMode: models.ParseModeHTML,
+		})
+		return nil
+	}
+
+	_, err = b.SendPhoto(ctx, &bot.SendPhotoParams{
+		ChatID:      chatID,
+		Photo:       &models.InputFileUpload{Filename: usageChartFile, Data: bytes.NewReader(chart)},
+		Caption:     caption,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: keyboard,
+	})
+	return err
+}
+
+// usageView renders a subscription's usage over the last days.
+// The chart is nil if the subscription has no samples yet.
+func usageView(userID int64, sub *api.UserSubscription, days int, lang string, deps commands.Deps) (string, []byte, *models.InlineKeyboardMarkup, error) {
+	loc := i18n.Localizer(lang)
+	name := html.EscapeString(subscriptionName(sub))
+
+	history, err := deps.Usage.Daily(userID, sub.ID, days, time.Now())
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if history == nil {
+		return i18n.TWithData(loc, "usage_no_data", map[string]any{"Name": name}), nil, nil, nil
+	}
+
+	chart, err := usage.Chart(history)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("render usage chart: %w", err)
+	}
+
+	weekdays := strings.Split(i18n.T(loc, "usage_weekdays"), ",")
+	var lines []string
+	var total int64
+	for _, d := range history {
+		weekday := d.Date.Weekday().String()[:3]
+		if len(weekdays) == 7 {
+			weekday = strings.TrimSpace(weekdays[d.Date.Weekday()])
+		}
+		lines = append(lines, i18n.TWithData(loc, "usage_day", map[string]any{
+			"Weekday": weekday,
+			"Date":    d.Date.Format("01-02"),
+			"Total":   i18n.FormatBytes(d.Total()),
+		}))
+		total += d.Total()
+	}
+
+	caption := i18n.TWithData(loc, "usage_caption", map[string]any{
+		"Name":  name,
+		"Days":  len(history),
+		"Lines": strings.Join(lines, "\n"),
+		"Total": i18n.FormatBytes(total),
+	})
+
+	var row []models.InlineKeyboardButton
+	for _, p := range usagePeriods {
+		text := i18n.TWithData(loc, "usage_period_button", map[string]any{"Days": p})
+		if p == len(history) {
+			text = "• " + text + " •"
+		}
+		row = append(row, models.InlineKeyboardButton{
+			Text:         text,
+			CallbackData: fmt.Sprintf("%s%d:%d", UsageCallbackPrefix, sub.ID, p),
+		})
+	}
+
+	return caption, chart, &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{row}}, nil
+}
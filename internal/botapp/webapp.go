@@ -0,0 +1,89 @@
+package botapp
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/auth"
+	"github.com/archnets/telegram-bot/internal/logger"
+)
+
+// maxInitDataSize limits Mini App auth request bodies.
+const maxInitDataSize = 16 << 10
+
+// WebAppAuthConfig holds Mini App token exchange options.
+type WebAppAuthConfig struct {
+	WebAppURL string        // Mini App URL; its origin is allowed to call the endpoint cross-origin
+	MaxAge    time.Duration // How old initData may be, e.g. 1h
+}
+
+// webAppAuthRequest is the body the Mini App posts: Telegram.WebApp.initData as is.
+type webAppAuthRequest struct {
+	InitData string `json:"init_data"`
+}
+
+// webAppAuthResponse carries the backend session of the Mini App user.
+type webAppAuthResponse struct {
+	Token string `json:"token"`
+}
+
+// WebAppAuthHandler returns an HTTP handler that exchanges validated Mini App
+// initData for a backend JWT, the same way the bot authenticates chat users.
+// The token also becomes the user's bot session.
+func WebAppAuthHandler(client *auth.Client, tokens *auth.TokenSource, cfg WebAppAuthConfig) http.Handler {
+	allowedOrigin := originOf(cfg.WebAppURL)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" && origin == allowedOrigin {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+			w.Header().Set("Vary", "Origin")
+		}
+
+		switch r.Method {
+		case http.MethodOptions:
+			w.WriteHeader(http.StatusNoContent)
+			return
+		case http.MethodPost:
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req webAppAuthRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxInitDataSize)).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		data, err := client.VerifyWebAppInitData(req.InitData, cfg.MaxAge, time.Now())
+		if err != nil {
+			logger.Debugf("Web App auth rejected: %v", err)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		token, err := tokens.Login(r.Context(), data.User)
+		if err != nil {
+			logger.ForUser(data.User.ID).Errorf("Web App auth failed: %v", err)
+			http.Error(w, "authentication failed", http.StatusBadGateway)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(webAppAuthResponse{Token: token})
+	})
+}
+
+// originOf returns the scheme and host of a URL, e.g. "https://app.example.com".
+func originOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
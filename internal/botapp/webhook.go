@@ -0,0 +1,86 @@
+package botapp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/go-telegram/bot"
+)
+
+// webhookSecretHeader is the header Telegram uses to send the webhook secret token.
+const webhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// WebhookConfig holds webhook delivery options.
+type WebhookConfig struct {
+	URL         string // Public base URL, e.g. "https://bot.example.com"
+	Path        string // Path updates are served on, e.g. "/telegram/webhook"
+	SecretToken string // Generated at startup if empty
+}
+
+// SetWebhook registers the webhook with Telegram.
+// If no secret token is configured, a random one is generated and stored in cfg.
+func SetWebhook(ctx context.Context, b *bot.Bot, cfg *WebhookConfig) error {
+	if cfg.URL == "" {
+		return fmt.Errorf("webhook URL is empty")
+	}
+
+	if cfg.SecretToken == "" {
+		secret, err := generateSecretToken()
+		if err != nil {
+			return fmt.Errorf("generate secret token: %w", err)
+		}
+		cfg.SecretToken = secret
+	}
+
+	_, err := b.SetWebhook(ctx, &bot.SetWebhookParams{
+		URL:         cfg.URL + cfg.Path,
+		SecretToken: cfg.SecretToken,
+	})
+	if err != nil {
+		return fmt.Errorf("set webhook: %w", err)
+	}
+	return nil
+}
+
+// DeleteWebhook removes the webhook so the bot can be switched back to polling.
+func DeleteWebhook(ctx context.Context, b *bot.Bot) error {
+	if _, err := b.DeleteWebhook(ctx, &bot.DeleteWebhookParams{}); err != nil {
+		return fmt.Errorf("delete webhook: %w", err)
+	}
+	return nil
+}
+
+// WebhookHandler returns an HTTP handler that validates the secret token
+// header and feeds updates into the bot. Every update is rejected if
+// secretToken is empty.
+func WebhookHandler(b *bot.Bot, secretToken string) http.Handler {
+	next := b.WebhookHandler()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		got := r.Header.Get(webhookSecretHeader)
+		if secretToken == "" || subtle.ConstantTimeCompare([]byte(got), []byte(secretToken)) != 1 {
+			http.Error(w, "invalid secret token", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r)
+	})
+}
+
+// generateSecretToken returns a random token valid for Telegram's secret_token field.
+func generateSecretToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,337 @@
+// Package broadcast delivers admin broadcasts to all known users with
+// rate limiting and restart-safe progress tracking.
+package broadcast
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/archnets/telegram-bot/internal/auth"
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/archnets/telegram-bot/internal/notify"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Broadcast statuses.
+const (
+	StatusDraft     = "draft"
+	StatusRunning   = "running"
+	StatusDone      = "done"
+	StatusCancelled = "cancelled"
+)
+
+// Recipient delivery statuses.
+const (
+	RecipientPending = "pending"
+	RecipientSent    = "sent"
+	RecipientFailed  = "failed"
+	RecipientBlocked = "blocked"
+	RecipientSkipped = "skipped" // User turned off announcements
+)
+
+const (
+	// sendInterval keeps delivery under Telegram's global limit (~30 msg/s).
+	sendInterval = 40 * time.Millisecond
+
+	// maxAttempts bounds retries of a single recipient after 429 responses.
+	maxAttempts = 3
+
+	// batchSize is the number of pending recipients loaded at once.
+	batchSize = 100
+
+	// titleLength is the maximum length of the title shown in the report.
+	titleLength = 40
+)
+
+// ErrNotDraft is returned when a broadcast can no longer be edited or started.
+var ErrNotDraft = errors.New("broadcast is not a draft")
+
+// Message is the content of a broadcast.
+type Message struct {
+	Text        string
+	Entities    []models.MessageEntity
+	PhotoFileID string // Sent as photo with Text as caption if set
+}
+
+// IsEmpty returns true if the message has no content.
+func (m Message) IsEmpty() bool {
+	return strings.TrimSpace(m.Text) == "" && m.PhotoFileID == ""
+}
+
+// Broadcast is a stored broadcast.
+type Broadcast struct {
+	ID      int64
+	AdminID int64
+	Message Message
+	Lang    string // Audience language filter; empty means all users
+	Status  string
+}
+
+// Stats holds delivery counts of a broadcast.
+type Stats struct {
+	Success int
+	Failed  int
+	Skipped int // Users who turned off announcements
+	Total   int
+}
+
+// Service manages broadcast drafts and delivers running broadcasts.
+type Service struct {
+	store    *SQLiteStore
+	sessions auth.SessionStore
+	prefs    *notify.Preferences
+	wake     chan struct{}
+}
+
+// NewService creates a new broadcast service.
+// Users who turned off announcements in prefs are skipped.
+func NewService(store *SQLiteStore, sessions auth.SessionStore, prefs *notify.Preferences) *Service {
+	return &Service{
+		store:    store,
+		sessions: sessions,
+		prefs:    prefs,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// CreateDraft stores a new broadcast draft.
+func (s *Service) CreateDraft(adminID int64, msg Message) (int64, error) {
+	return s.store.create(adminID, msg)
+}
+
+// Get returns a broadcast by ID.
+func (s *Service) Get(id int64) (*Broadcast, error) {
+	return s.store.get(id)
+}
+
+// SetAudience sets the language filter of a draft (empty for all users).
+func (s *Service) SetAudience(id int64, lang string) error {
+	return s.store.setLang(id, lang)
+}
+
+// CountAudience returns the number of users matching a language filter.
+func (s *Service) CountAudience(lang string) (int, error) {
+	return s.store.countAudience(lang)
+}
+
+// Start queues a draft for delivery and returns the number of recipients.
+func (s *Service) Start(id int64) (int, error) {
+	bc, err := s.store.get(id)
+	if err != nil {
+		return 0, err
+	}
+
+	total, err := s.store.start(id, bc.Lang)
+	if err != nil {
+		return 0, err
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return total, nil
+}
+
+// Cancel cancels a draft or stops a running broadcast.
+func (s *Service) Cancel(id int64) error {
+	bc, err := s.store.get(id)
+	if err != nil {
+		return err
+	}
+	if bc.Status != StatusDraft && bc.Status != StatusRunning {
+		return ErrNotDraft
+	}
+	return s.store.setStatus(id, StatusCancelled)
+}
+
+// Run delivers running broadcasts until ctx is cancelled.
+// Broadcasts interrupted by a restart are resumed on start.
+func (s *Service) Run(ctx context.Context, b *bot.Bot) {
+	throttle := time.NewTicker(sendInterval)
+	defer throttle.Stop()
+
+	for {
+		ids, err := s.store.listRunning()
+		if err != nil {
+			logger.Errorf("List running broadcasts: %v", err)
+		}
+		for _, id := range ids {
+			s.process(ctx, b, id, throttle.C)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		}
+	}
+}
+
+// process delivers a broadcast until it is finished, cancelled or ctx is done.
+func (s *Service) process(ctx context.Context, b *bot.Bot, id int64, throttle <-chan time.Time) {
+	logger.Infof("Broadcast %d: delivering", id)
+
+	for ctx.Err() == nil {
+		bc, err := s.store.get(id)
+		if err != nil {
+			logger.Errorf("Broadcast %d: load failed: %v", id, err)
+			return
+		}
+		if bc.Status != StatusRunning {
+			logger.Infof("Broadcast %d: stopped (%s)", id, bc.Status)
+			s.report(ctx, b, bc)
+			return
+		}
+
+		batch, err := s.store.pendingRecipients(id, batchSize)
+		if err != nil {
+			logger.Errorf("Broadcast %d: load recipients failed: %v", id, err)
+			return
+		}
+		if len(batch) == 0 {
+			if err := s.store.setStatus(id, StatusDone); err != nil {
+				logger.Errorf("Broadcast %d: finish failed: %v", id, err)
+			}
+			s.report(ctx, b, bc)
+			return
+		}
+
+		for _, chatID := range batch {
+			status, ok := RecipientSkipped, true
+			if s.prefs == nil || s.prefs.Allows(ctx, chatID, notify.CategoryAnnouncements) {
+				status, ok = s.deliver(ctx, b, bc, chatID, throttle)
+			}
+			if !ok {
+				return // Shutting down; recipient stays pending
+			}
+			if err := s.store.setRecipientStatus(id, chatID, status); err != nil {
+				logger.Errorf("Broadcast %d: update recipient failed: %v", id, err)
+				return
+			}
+		}
+	}
+}
+
+// deliver sends the broadcast to a single chat, retrying on rate limits.
+// Returns false if ctx was cancelled before the outcome was known.
+func (s *Service) deliver(ctx context.Context, b *bot.Bot, bc *Broadcast, chatID int64, throttle <-chan time.Time) (string, bool) {
+	lg := logger.ForUser(chatID)
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		select {
+		case <-ctx.Done():
+			return "", false
+		case <-throttle:
+		}
+
+		err := Send(ctx, b, chatID, bc.Message)
+		if err == nil {
+			return RecipientSent, true
+		}
+
+		var tooMany *bot.TooManyRequestsError
+		switch {
+		case errors.As(err, &tooMany):
+			wait := time.Duration(tooMany.RetryAfter) * time.Second
+			lg.Warnf("Broadcast %d: rate limited, retrying in %s", bc.ID, wait)
+			if !sleep(ctx, wait) {
+				return "", false
+			}
+		case errors.Is(err, bot.ErrorForbidden):
+			// User blocked the bot; forget them
+			s.sessions.Delete(chatID)
+			lg.Infof("Broadcast %d: user blocked the bot, session removed", bc.ID)
+			return RecipientBlocked, true
+		default:
+			lg.Warnf("Broadcast %d: send failed: %v", bc.ID, err)
+			return RecipientFailed, true
+		}
+	}
+
+	return RecipientFailed, true
+}
+
+// report sends the delivery report to the admin who created the broadcast.
+func (s *Service) report(ctx context.Context, b *bot.Bot, bc *Broadcast) {
+	stats, err := s.store.stats(bc.ID)
+	if err != nil {
+		logger.Errorf("Broadcast %d: stats failed: %v", bc.ID, err)
+		return
+	}
+
+	lang := s.sessions.GetLang(bc.AdminID)
+	if lang == "" {
+		lang = "en"
+	}
+	loc := i18n.Localizer(lang)
+
+	_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: bc.AdminID,
+		Text: i18n.TWithData(loc, "admin_broadcast_report", map[string]any{
+			"Title":   html.EscapeString(title(bc.Message)),
+			"Success": stats.Success,
+			"Failed":  stats.Failed,
+			"Skipped": stats.Skipped,
+			"Total":   stats.Total,
+		}),
+		ParseMode: models.ParseModeHTML,
+	})
+	logger.Infof("Broadcast %d: %d sent, %d failed, %d skipped, %d total", bc.ID, stats.Success, stats.Failed, stats.Skipped, stats.Total)
+}
+
+// Send sends a broadcast message to a chat.
+func Send(ctx context.Context, b *bot.Bot, chatID int64, msg Message) error {
+	if msg.PhotoFileID != "" {
+		_, err := b.SendPhoto(ctx, &bot.SendPhotoParams{
+			ChatID:          chatID,
+			Photo:           &models.InputFileString{Data: msg.PhotoFileID},
+			Caption:         msg.Text,
+			CaptionEntities: msg.Entities,
+		})
+		return err
+	}
+
+	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:   chatID,
+		Text:     msg.Text,
+		Entities: msg.Entities,
+	})
+	return err
+}
+
+// IsNotFound returns true if err means the broadcast does not exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, sql.ErrNoRows)
+}
+
+// title returns a short title for the report: the first line of the text.
+func title(msg Message) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(msg.Text), "\n")
+	if line == "" {
+		return "📷"
+	}
+	if utf8.RuneCountInString(line) > titleLength {
+		return string([]rune(line)[:titleLength]) + "…"
+	}
+	return line
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,186 @@
+package broadcast
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// SQLiteStore persists broadcasts and their delivery progress.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore creates a new SQLite broadcast store.
+// The db connection should already have migrations applied.
+func NewSQLiteStore(db *sql.DB) *SQLiteStore {
+	return &SQLiteStore{db: db}
+}
+
+func (s *SQLiteStore) create(adminID int64, msg Message) (int64, error) {
+	entities, err := json.Marshal(msg.Entities)
+	if err != nil {
+		return 0, fmt.Errorf("marshal entities: %w", err)
+	}
+
+	res, err := s.db.Exec(`
+		INSERT INTO broadcasts (admin_id, text, entities, photo_file_id, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, adminID, msg.Text, string(entities), msg.PhotoFileID, StatusDraft, time.Now().Unix())
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (s *SQLiteStore) get(id int64) (*Broadcast, error) {
+	var bc Broadcast
+	var entities string
+
+	err := s.db.QueryRow(`
+		SELECT id, admin_id, text, entities, photo_file_id, lang, status
+		FROM broadcasts WHERE id = ?
+	`, id).Scan(&bc.ID, &bc.AdminID, &bc.Message.Text, &entities, &bc.Message.PhotoFileID, &bc.Lang, &bc.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	if entities != "" {
+		var list []models.MessageEntity
+		if err := json.Unmarshal([]byte(entities), &list); err != nil {
+			return nil, fmt.Errorf("unmarshal entities: %w", err)
+		}
+		bc.Message.Entities = list
+	}
+	return &bc, nil
+}
+
+func (s *SQLiteStore) setLang(id int64, lang string) error {
+	_, err := s.db.Exec(`UPDATE broadcasts SET lang = ? WHERE id = ? AND status = ?`, lang, id, StatusDraft)
+	return err
+}
+
+func (s *SQLiteStore) setStatus(id int64, status string) error {
+	var finishedAt int64
+	if status == StatusDone || status == StatusCancelled {
+		finishedAt = time.Now().Unix()
+	}
+	_, err := s.db.Exec(`UPDATE broadcasts SET status = ?, finished_at = ? WHERE id = ?`, status, finishedAt, id)
+	return err
+}
+
+// start snapshots the recipients of a draft and marks it running.
+func (s *SQLiteStore) start(id int64, lang string) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(`UPDATE broadcasts SET status = ? WHERE id = ? AND status = ?`, StatusRunning, id, StatusDraft)
+	if err != nil {
+		return 0, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return 0, ErrNotDraft
+	}
+
+	res, err = tx.Exec(`
+		INSERT OR IGNORE INTO broadcast_recipients (broadcast_id, telegram_id)
+		SELECT ?, telegram_id FROM sessions WHERE ? = '' OR lang = ?
+	`, id, lang, lang)
+	if err != nil {
+		return 0, err
+	}
+	total, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(total), tx.Commit()
+}
+
+func (s *SQLiteStore) countAudience(lang string) (int, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM sessions WHERE ? = '' OR lang = ?`, lang, lang).Scan(&n)
+	return n, err
+}
+
+func (s *SQLiteStore) listRunning() ([]int64, error) {
+	rows, err := s.db.Query(`SELECT id FROM broadcasts WHERE status = ? ORDER BY id`, StatusRunning)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (s *SQLiteStore) pendingRecipients(id int64, limit int) ([]int64, error) {
+	rows, err := s.db.Query(`
+		SELECT telegram_id FROM broadcast_recipients
+		WHERE broadcast_id = ? AND status = ?
+		ORDER BY telegram_id LIMIT ?
+	`, id, RecipientPending, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var chatID int64
+		if err := rows.Scan(&chatID); err != nil {
+			return nil, err
+		}
+		ids = append(ids, chatID)
+	}
+	return ids, rows.Err()
+}
+
+func (s *SQLiteStore) setRecipientStatus(id, chatID int64, status string) error {
+	_, err := s.db.Exec(`
+		UPDATE broadcast_recipients SET status = ? WHERE broadcast_id = ? AND telegram_id = ?
+	`, status, id, chatID)
+	return err
+}
+
+func (s *SQLiteStore) stats(id int64) (Stats, error) {
+	rows, err := s.db.Query(`
+		SELECT status, COUNT(*) FROM broadcast_recipients WHERE broadcast_id = ? GROUP BY status
+	`, id)
+	if err != nil {
+		return Stats{}, err
+	}
+	defer rows.Close()
+
+	var st Stats
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return Stats{}, err
+		}
+		st.Total += n
+		switch status {
+		case RecipientSent:
+			st.Success += n
+		case RecipientFailed, RecipientBlocked:
+			st.Failed += n
+		case RecipientSkipped:
+			st.Skipped += n
+		}
+	}
+	return st, rows.Err()
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"sort"
 	"strconv"
 	"strings"
 
@@ -49,3 +50,13 @@ func (s *AuthService) IsAdmin(tgID int64) bool {
 	_, ok := s.admins[tgID]
 	return ok
 }
+
+// AdminIDs returns all admin telegram IDs in ascending order.
+func (s *AuthService) AdminIDs() []int64 {
+	ids := make([]int64, 0, len(s.admins))
+	for id := range s.admins {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -1,15 +1,92 @@
 package core
 
-// SubscriptionService is a placeholder for subscription-related operations.
-type SubscriptionService struct{}
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/api"
+)
+
+// SubscriptionService provides subscription and account queries backed by the API.
+type SubscriptionService struct {
+	api *api.Client
+}
 
 // NewSubscriptionService creates a new subscription service.
-// The `_ any` parameter is for backwards compatibility.
-func NewSubscriptionService(_ any) *SubscriptionService {
-	return &SubscriptionService{}
+func NewSubscriptionService(client *api.Client) *SubscriptionService {
+	return &SubscriptionService{api: client}
+}
+
+// AccountStatus summarizes a user's account.
+type AccountStatus struct {
+	Email               string
+	Balance             int64
+	ReferCode           string
+	ActiveSubscriptions int
+	NearestExpiry       time.Time // Zero if no active subscription expires
+}
+
+// DaysLeft returns the number of whole days until the nearest expiry (rounded up).
+// Returns -1 if no active subscription expires.
+func (s *AccountStatus) DaysLeft(now time.Time) int {
+	if s.NearestExpiry.IsZero() {
+		return -1
+	}
+	left := s.NearestExpiry.Sub(now)
+	if left <= 0 {
+		return 0
+	}
+	return int(math.Ceil(left.Hours() / 24))
+}
+
+// GetSubscriptions fetches the user's subscriptions.
+func (s *SubscriptionService) GetSubscriptions(ctx context.Context, token string) ([]api.UserSubscription, error) {
+	return s.api.GetUserSubscriptions(ctx, token)
+}
+
+// GetStatus fetches the user's info and subscriptions and builds an account summary.
+func (s *SubscriptionService) GetStatus(ctx context.Context, token string) (*AccountStatus, error) {
+	info, err := s.api.GetUserInfo(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("get user info: %w", err)
+	}
+
+	subs, err := s.api.GetUserSubscriptions(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("get subscriptions: %w", err)
+	}
+
+	status := &AccountStatus{
+		Email:     info.Email,
+		Balance:   info.Balance,
+		ReferCode: info.ReferCode,
+	}
+
+	now := time.Now()
+	for _, sub := range subs {
+		if !IsSubscriptionActive(sub, now) {
+			continue
+		}
+		status.ActiveSubscriptions++
+
+		if sub.ExpireTime == 0 {
+			continue // Never expires
+		}
+		expiry := time.UnixMilli(sub.ExpireTime)
+		if status.NearestExpiry.IsZero() || expiry.Before(status.NearestExpiry) {
+			status.NearestExpiry = expiry
+		}
+	}
+
+	return status, nil
 }
 
-// GetStatusText returns subscription status (placeholder).
-func (s *SubscriptionService) GetStatusText(tgID int64) string {
-	return "Status feature coming soon"
+// IsSubscriptionActive returns true if the subscription is active and not yet expired.
+func IsSubscriptionActive(sub api.UserSubscription, now time.Time) bool {
+	if sub.Status != api.UserSubscribeStatusActive {
+		return false
+	}
+	return sub.ExpireTime == 0 || time.UnixMilli(sub.ExpireTime).After(now)
 }
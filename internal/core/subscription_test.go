@@ -0,0 +1,55 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/api/apitest"
+	"github.com/archnets/telegram-bot/internal/core"
+)
+
+// ExecuteWithAuth recognizes an expired session by the code of the wrapped
+// api.Error, so GetStatus must keep it reachable through errors.As.
+func TestGetStatusKeepsErrorCode(t *testing.T) {
+	srv := apitest.NewServer("bot-token")
+	defer srv.Close()
+
+	token := srv.AddUser(apitest.User{TelegramID: 42})
+	srv.ExpireTokens()
+
+	svc := core.NewSubscriptionService(api.NewClient(srv.URL, time.Second))
+	_, err := svc.GetStatus(context.Background(), token)
+	if !api.IsAuthError(api.ErrorCode(err)) {
+		t.Fatalf("err = %v, want an auth error", err)
+	}
+}
+
+func TestGetStatus(t *testing.T) {
+	srv := apitest.NewServer("bot-token")
+	defer srv.Close()
+
+	now := time.Now()
+	expiry := now.Add(36 * time.Hour)
+	token := srv.AddUser(apitest.User{
+		TelegramID: 42,
+		Info:       api.UserInfo{Email: "user@example.com", Balance: 250},
+		Subscriptions: []api.UserSubscription{
+			{ID: 1, Status: 1, ExpireTime: expiry.UnixMilli()},
+			{ID: 2, Status: 3, ExpireTime: now.Add(-time.Hour).UnixMilli()},
+		},
+	})
+
+	svc := core.NewSubscriptionService(api.NewClient(srv.URL, time.Second))
+	status, err := svc.GetStatus(context.Background(), token)
+	if err != nil {
+		t.Fatalf("GetStatus: %v", err)
+	}
+	if status.Email != "user@example.com" || status.ActiveSubscriptions != 1 {
+		t.Errorf("status = %+v", status)
+	}
+	if days := status.DaysLeft(now); days != 2 {
+		t.Errorf("DaysLeft = %d, want 2", days)
+	}
+}
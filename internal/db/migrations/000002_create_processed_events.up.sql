@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS processed_events (
+    event_key TEXT PRIMARY KEY,
+    processed_at INTEGER NOT NULL
+);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS node_status (
+    node_id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    country TEXT NOT NULL DEFAULT '',
+    city TEXT NOT NULL DEFAULT '',
+    online INTEGER NOT NULL DEFAULT 1,
+    last_reported_at INTEGER NOT NULL,
+    reporting_since INTEGER NOT NULL DEFAULT 0,
+    changed_at INTEGER NOT NULL
+);
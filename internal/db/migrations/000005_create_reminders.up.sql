@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS reminders_sent (
+    user_subscribe_id INTEGER NOT NULL,
+    reminder TEXT NOT NULL,
+    sent_at INTEGER NOT NULL,
+    PRIMARY KEY (user_subscribe_id, reminder)
+);
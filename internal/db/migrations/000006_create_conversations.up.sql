@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS conversations (
+    telegram_id INTEGER PRIMARY KEY,
+    state TEXT NOT NULL,
+    data TEXT NOT NULL DEFAULT '{}',
+    expires_at INTEGER NOT NULL
+);
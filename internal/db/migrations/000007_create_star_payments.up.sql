@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS star_payments (
+    charge_id TEXT PRIMARY KEY,
+    telegram_id INTEGER NOT NULL,
+    order_no TEXT NOT NULL,
+    stars INTEGER NOT NULL,
+    finalized INTEGER NOT NULL DEFAULT 0,
+    refunded INTEGER NOT NULL DEFAULT 0,
+    held INTEGER NOT NULL DEFAULT 0,
+    created_at INTEGER NOT NULL
+);
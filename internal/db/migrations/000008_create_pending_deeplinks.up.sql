@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS pending_deeplinks (
+    telegram_id INTEGER PRIMARY KEY,
+    payload TEXT NOT NULL,
+    expires_at INTEGER NOT NULL
+);
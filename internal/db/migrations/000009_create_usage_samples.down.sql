@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_usage_samples_sampled_at;
+DROP TABLE IF EXISTS usage_samples;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS usage_samples (
+    user_subscribe_id INTEGER NOT NULL,
+    telegram_id INTEGER NOT NULL,
+    download INTEGER NOT NULL,
+    upload INTEGER NOT NULL,
+    sampled_at INTEGER NOT NULL,
+    PRIMARY KEY (user_subscribe_id, sampled_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_samples_sampled_at ON usage_samples (sampled_at);
@@ -0,0 +1,189 @@
+// Package monitor tracks node heartbeats and alerts admins when nodes go
+// offline or come back online.
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"sync"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/archnets/telegram-bot/internal/notify"
+)
+
+const timeLayout = "2006-01-02 15:04:05"
+
+// Config holds monitoring options.
+type Config struct {
+	OfflineGrace  time.Duration // Time without reports before a node is offline
+	OnlineGrace   time.Duration // Time a node must report continuously to be back online
+	CheckInterval time.Duration // How often transitions are checked
+}
+
+// Monitor detects node online/offline transitions and alerts admins.
+type Monitor struct {
+	mu       sync.Mutex
+	store    *SQLiteStore
+	notifier *notify.Notifier
+	cfg      Config
+}
+
+// New creates a new node monitor.
+func New(store *SQLiteStore, notifier *notify.Notifier, cfg Config) *Monitor {
+	if cfg.OfflineGrace <= 0 {
+		cfg.OfflineGrace = 3 * time.Minute
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = 30 * time.Second
+	}
+	return &Monitor{
+		store:    store,
+		notifier: notifier,
+		cfg:      cfg,
+	}
+}
+
+// Report records a node heartbeat.
+func (m *Monitor) Report(node api.NodeStatus) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	reportedAt := now
+	if node.LastReportedAt != 0 {
+		reportedAt = time.Unix(node.LastReportedAt, 0)
+	}
+
+	st, err := m.store.get(node.ID)
+	if err != nil {
+		return fmt.Errorf("get node state: %w", err)
+	}
+
+	if st == nil {
+		// First sighting: assume online, nothing to alert
+		st = &nodeState{NodeID: node.ID, Online: true, LastReportedAt: reportedAt, ChangedAt: now}
+	}
+
+	if !st.Online && (st.ReportingSince.IsZero() || reportedAt.Sub(st.LastReportedAt) > m.cfg.OfflineGrace) {
+		// Start (or restart after a gap) the recovery window
+		st.ReportingSince = reportedAt
+	}
+
+	if reportedAt.After(st.LastReportedAt) {
+		st.LastReportedAt = reportedAt
+	}
+	updateNodeInfo(st, node)
+
+	return m.store.save(st)
+}
+
+// Run checks nodes for transitions until ctx is cancelled.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.check(ctx)
+		}
+	}
+}
+
+// transition is a detected state change waiting to be announced.
+type transition struct {
+	state  nodeState
+	online bool
+	at     time.Time
+}
+
+func (m *Monitor) check(ctx context.Context) {
+	transitions, err := m.detect(time.Now())
+	if err != nil {
+		logger.Errorf("Node monitor check failed: %v", err)
+		return
+	}
+
+	for _, t := range transitions {
+		m.alert(ctx, t)
+	}
+}
+
+// detect applies state transitions and returns them.
+// States are saved before alerting so a restart never re-fires an alert.
+func (m *Monitor) detect(now time.Time) ([]transition, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	states, err := m.store.list()
+	if err != nil {
+		return nil, fmt.Errorf("list node states: %w", err)
+	}
+
+	var transitions []transition
+	for _, st := range states {
+		silent := now.Sub(st.LastReportedAt)
+
+		switch {
+		case st.Online && silent > m.cfg.OfflineGrace:
+			st.Online = false
+			st.ReportingSince = time.Time{}
+			st.ChangedAt = now
+			transitions = append(transitions, transition{state: *st, online: false, at: now})
+
+		case !st.Online && !st.ReportingSince.IsZero() && silent <= m.cfg.OfflineGrace &&
+			st.LastReportedAt.Sub(st.ReportingSince) >= m.cfg.OnlineGrace:
+			onlineAt := st.ReportingSince
+			st.Online = true
+			st.ReportingSince = time.Time{}
+			st.ChangedAt = now
+			transitions = append(transitions, transition{state: *st, online: true, at: onlineAt})
+
+		default:
+			continue
+		}
+
+		if err := m.store.save(st); err != nil {
+			return transitions, fmt.Errorf("save node %d: %w", st.NodeID, err)
+		}
+	}
+
+	return transitions, nil
+}
+
+func (m *Monitor) alert(ctx context.Context, t transition) {
+	data := map[string]any{
+		"ServerName": html.EscapeString(t.state.Name),
+		"Country":    html.EscapeString(t.state.Country),
+		"City":       html.EscapeString(t.state.City),
+	}
+
+	if t.online {
+		data["OnlineAt"] = t.at.Format(timeLayout)
+		m.notifier.SendToAdmins(ctx, "server_online", data)
+		logger.Infof("Node %d (%s) is back online", t.state.NodeID, t.state.Name)
+		return
+	}
+
+	data["LastReportedAt"] = t.state.LastReportedAt.Format(timeLayout)
+	data["DetectedAt"] = t.at.Format(timeLayout)
+	m.notifier.SendToAdmins(ctx, "server_offline", data)
+	logger.Warnf("Node %d (%s) is offline", t.state.NodeID, t.state.Name)
+}
+
+func updateNodeInfo(st *nodeState, node api.NodeStatus) {
+	if node.Name != "" {
+		st.Name = node.Name
+	}
+	if node.Country != "" {
+		st.Country = node.Country
+	}
+	if node.City != "" {
+		st.City = node.City
+	}
+}
@@ -0,0 +1,84 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/archnets/telegram-bot/internal/notify"
+)
+
+// EventNodeHeartbeat is the backend event carrying a node heartbeat.
+const EventNodeHeartbeat = "node.heartbeat"
+
+// RegisterEventHandlers registers the heartbeat event handler.
+func RegisterEventHandlers(r *notify.Router, m *Monitor) {
+	r.Register(EventNodeHeartbeat, func(ctx context.Context, data json.RawMessage) error {
+		var node api.NodeStatus
+		if err := json.Unmarshal(data, &node); err != nil {
+			return fmt.Errorf("%w: %v", notify.ErrInvalidEvent, err)
+		}
+		if node.ID == 0 {
+			return fmt.Errorf("%w: missing node id", notify.ErrInvalidEvent)
+		}
+		return m.Report(node)
+	})
+}
+
+// Poller periodically fetches node statuses from the backend using admin credentials.
+type Poller struct {
+	api      *api.Client
+	admin    *api.AdminSession
+	monitor  *Monitor
+	interval time.Duration
+}
+
+// NewPoller creates a new node status poller.
+func NewPoller(client *api.Client, admin *api.AdminSession, m *Monitor, interval time.Duration) *Poller {
+	return &Poller{
+		api:      client,
+		admin:    admin,
+		monitor:  m,
+		interval: interval,
+	}
+}
+
+// Run polls node statuses until ctx is cancelled.
+func (p *Poller) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.poll(ctx); err != nil {
+			logger.Warnf("Node status poll failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Poller) poll(ctx context.Context) error {
+	var nodes []api.NodeStatus
+	err := p.admin.Do(ctx, func(token string) error {
+		var err error
+		nodes, err = p.api.GetNodeStatuses(ctx, token)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, node := range nodes {
+		if err := p.monitor.Report(node); err != nil {
+			return fmt.Errorf("report node %d: %w", node.ID, err)
+		}
+	}
+	return nil
+}
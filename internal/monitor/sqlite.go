@@ -0,0 +1,105 @@
+package monitor
+
+import (
+	"database/sql"
+	"time"
+)
+
+// nodeState is the persisted monitoring state of a node.
+type nodeState struct {
+	NodeID         int64
+	Name           string
+	Country        string
+	City           string
+	Online         bool
+	LastReportedAt time.Time
+	ReportingSince time.Time // First report after an outage; zero while online
+	ChangedAt      time.Time
+}
+
+// SQLiteStore persists node states so restarts don't re-fire alerts.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore creates a new SQLite node state store.
+// The db connection should already have migrations applied.
+func NewSQLiteStore(db *sql.DB) *SQLiteStore {
+	return &SQLiteStore{db: db}
+}
+
+// get returns the state of a node, or nil if it has never been seen.
+func (s *SQLiteStore) get(nodeID int64) (*nodeState, error) {
+	row := s.db.QueryRow(`
+		SELECT node_id, name, country, city, online, last_reported_at, reporting_since, changed_at
+		FROM node_status WHERE node_id = ?
+	`, nodeID)
+
+	st, err := scanState(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return st, err
+}
+
+// list returns the states of all known nodes.
+func (s *SQLiteStore) list() ([]*nodeState, error) {
+	rows, err := s.db.Query(`
+		SELECT node_id, name, country, city, online, last_reported_at, reporting_since, changed_at
+		FROM node_status ORDER BY node_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var states []*nodeState
+	for rows.Next() {
+		st, err := scanState(rows)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, st)
+	}
+	return states, rows.Err()
+}
+
+// save inserts or replaces a node state.
+func (s *SQLiteStore) save(st *nodeState) error {
+	_, err := s.db.Exec(`
+		INSERT OR REPLACE INTO node_status
+			(node_id, name, country, city, online, last_reported_at, reporting_since, changed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, st.NodeID, st.Name, st.Country, st.City, st.Online,
+		st.LastReportedAt.Unix(), unixOrZero(st.ReportingSince), st.ChangedAt.Unix())
+	return err
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanState(row scanner) (*nodeState, error) {
+	var st nodeState
+	var lastReported, reportingSince, changedAt int64
+
+	err := row.Scan(&st.NodeID, &st.Name, &st.Country, &st.City, &st.Online,
+		&lastReported, &reportingSince, &changedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	st.LastReportedAt = time.Unix(lastReported, 0)
+	if reportingSince != 0 {
+		st.ReportingSince = time.Unix(reportingSince, 0)
+	}
+	st.ChangedAt = time.Unix(changedAt, 0)
+	return &st, nil
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/archnets/telegram-bot/internal/logger"
+)
+
+// ErrInvalidEvent is returned by handlers for malformed event data.
+// The router responds with 400 so the backend does not retry.
+var ErrInvalidEvent = errors.New("invalid event")
+
+// Event is the envelope the backend posts to the notification endpoint.
+type Event struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// EventHandler processes the data of a single event type.
+// Returning an error (other than ErrInvalidEvent) makes the backend retry.
+type EventHandler func(ctx context.Context, data json.RawMessage) error
+
+// Router dispatches backend events to registered handlers.
+type Router struct {
+	handlers map[string]EventHandler
+}
+
+// NewRouter creates an empty event router.
+func NewRouter() *Router {
+	return &Router{handlers: make(map[string]EventHandler)}
+}
+
+// Register adds a handler for an event type.
+func (r *Router) Register(eventType string, h EventHandler) {
+	r.handlers[eventType] = h
+}
+
+// ServeHTTP decodes an event and dispatches it to its handler.
+// Requests should be signature-checked before reaching the router.
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var ev Event
+	if err := json.NewDecoder(req.Body).Decode(&ev); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	h, ok := r.handlers[ev.Type]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown event type %q", ev.Type), http.StatusBadRequest)
+		return
+	}
+
+	if err := h(req.Context(), ev.Data); err != nil {
+		if errors.Is(err, ErrInvalidEvent) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logger.Errorf("Event %s failed: %v", ev.Type, err)
+		http.Error(w, "event processing failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"ok":true}`))
+}
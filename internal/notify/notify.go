@@ -0,0 +1,83 @@
+// Package notify delivers bot-initiated messages to users and admins and
+// ingests events pushed by the backend.
+package notify
+
+import (
+	"context"
+	"errors"
+
+	"github.com/archnets/telegram-bot/internal/auth"
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// defaultLang is used for users without a saved language.
+const defaultLang = "en"
+
+// Notifier sends localized messages to users and admins.
+type Notifier struct {
+	bot      *bot.Bot
+	sessions auth.SessionStore
+	prefs    *Preferences
+	admins   []int64
+}
+
+// NewNotifier creates a new notifier.
+// prefs may be nil, in which case every category is allowed.
+func NewNotifier(b *bot.Bot, sessions auth.SessionStore, prefs *Preferences, admins []int64) *Notifier {
+	return &Notifier{
+		bot:      b,
+		sessions: sessions,
+		prefs:    prefs,
+		admins:   admins,
+	}
+}
+
+// Allows returns true if the user wants messages of the category.
+func (n *Notifier) Allows(ctx context.Context, telegramID int64, c Category) bool {
+	if n.prefs == nil {
+		return true
+	}
+	return n.prefs.Allows(ctx, telegramID, c)
+}
+
+// SendToUser renders a message in the user's saved language and sends it.
+func (n *Notifier) SendToUser(ctx context.Context, telegramID int64, messageID string, data map[string]any) error {
+	loc := i18n.Localizer(n.UserLang(telegramID))
+	return n.send(ctx, telegramID, i18n.TWithData(loc, messageID, data))
+}
+
+// SendToAdmins sends a message to every admin in their own language.
+// Delivery failures are logged and do not stop the remaining admins.
+func (n *Notifier) SendToAdmins(ctx context.Context, messageID string, data map[string]any) {
+	for _, id := range n.admins {
+		if err := n.SendToUser(ctx, id, messageID, data); err != nil {
+			logger.ForUser(id).Warnf("Admin notification %s failed: %v", messageID, err)
+		}
+	}
+}
+
+// UserLang returns the user's saved language, or the default language.
+func (n *Notifier) UserLang(telegramID int64) string {
+	if lang := n.sessions.GetLang(telegramID); lang != "" {
+		return lang
+	}
+	return defaultLang
+}
+
+func (n *Notifier) send(ctx context.Context, chatID int64, text string) error {
+	_, err := n.bot.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    chatID,
+		Text:      text,
+		ParseMode: models.ParseModeHTML,
+	})
+	return err
+}
+
+// IsBlocked returns true if the error means the user blocked the bot
+// or the chat no longer exists.
+func IsBlocked(err error) bool {
+	return errors.Is(err, bot.ErrorForbidden)
+}
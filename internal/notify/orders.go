@@ -0,0 +1,133 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/archnets/telegram-bot/internal/logger"
+)
+
+// Order event types posted by the backend.
+const (
+	EventOrderPurchase = "order.purchase"
+	EventOrderRenewal  = "order.renewal"
+	EventOrderRecharge = "order.recharge"
+	EventOrderReset    = "order.reset"
+)
+
+// orderMessages maps order event types to the user-facing message ID.
+var orderMessages = map[string]string{
+	EventOrderPurchase: "purchase_success",
+	EventOrderRenewal:  "renewal_success",
+	EventOrderRecharge: "recharge_success",
+	EventOrderReset:    "reset_success",
+}
+
+// OrderEvent is the data of an order event.
+type OrderEvent struct {
+	OrderNo       string `json:"order_no"`
+	TelegramID    int64  `json:"telegram_id"` // 0 if the user has no Telegram binding
+	UserEmail     string `json:"user_email"`
+	SubscribeName string `json:"subscribe_name"`
+	Amount        int64  `json:"amount"` // Minor units (cents)
+	PaymentMethod string `json:"payment_method"`
+	ExpireTime    int64  `json:"expire_time"` // Unix milliseconds
+	Balance       int64  `json:"balance"`     // Balance after recharge (cents)
+	PaidAt        int64  `json:"paid_at"`     // Unix milliseconds
+}
+
+// RegisterOrderHandlers registers handlers for all order event types.
+func RegisterOrderHandlers(r *Router, n *Notifier, store *SQLiteStore) {
+	for eventType := range orderMessages {
+		r.Register(eventType, orderHandler(eventType, n, store))
+	}
+}
+
+// orderHandler delivers an order event to the user and admins exactly once per order number.
+func orderHandler(eventType string, n *Notifier, store *SQLiteStore) EventHandler {
+	return func(ctx context.Context, data json.RawMessage) error {
+		var ev OrderEvent
+		if err := json.Unmarshal(data, &ev); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+		}
+		if ev.OrderNo == "" {
+			return fmt.Errorf("%w: missing order_no", ErrInvalidEvent)
+		}
+
+		return deliverOrder(ctx, n, store, eventType, &ev)
+	}
+}
+
+// deliverOrder sends an order notification to the user and admins once per order number.
+// Shared by backend events and the order watcher so each order is announced once.
+func deliverOrder(ctx context.Context, n *Notifier, store *SQLiteStore, eventType string, ev *OrderEvent) error {
+	key := eventType + ":" + ev.OrderNo
+	claimed, err := store.Claim(key)
+	if err != nil {
+		return fmt.Errorf("claim event: %w", err)
+	}
+	if !claimed {
+		logger.Infof("Duplicate %s event for order %s ignored", eventType, ev.OrderNo)
+		return nil
+	}
+
+	if ev.TelegramID != 0 && n.Allows(ctx, ev.TelegramID, CategoryOrders) {
+		err := n.SendToUser(ctx, ev.TelegramID, orderMessages[eventType], orderUserData(ev))
+		if err != nil && !IsBlocked(err) {
+			// Let the backend retry the whole event
+			_ = store.Release(key)
+			return fmt.Errorf("notify user: %w", err)
+		}
+	}
+
+	n.SendToAdmins(ctx, "admin_order_notify", orderAdminData(ev))
+	logger.ForUser(ev.TelegramID).Infof("Order %s notification delivered (%s)", ev.OrderNo, eventType)
+	return nil
+}
+
+func orderUserData(ev *OrderEvent) map[string]any {
+	return map[string]any{
+		"OrderNo":       html.EscapeString(ev.OrderNo),
+		"SubscribeName": html.EscapeString(ev.SubscribeName),
+		"OrderAmount":   i18n.FormatAmount(ev.Amount),
+		"ExpireTime":    formatExpire(ev.ExpireTime),
+		"PaymentMethod": html.EscapeString(ev.PaymentMethod),
+		"Time":          formatTime(ev.PaidAt),
+		"Balance":       i18n.FormatAmount(ev.Balance),
+	}
+}
+
+func orderAdminData(ev *OrderEvent) map[string]any {
+	name := ev.SubscribeName
+	if name == "" {
+		name = "—"
+	}
+	return map[string]any{
+		"OrderNo":       html.EscapeString(ev.OrderNo),
+		"UserEmail":     html.EscapeString(ev.UserEmail),
+		"OrderAmount":   i18n.FormatAmount(ev.Amount),
+		"SubscribeName": html.EscapeString(name),
+		"PaymentMethod": html.EscapeString(ev.PaymentMethod),
+	}
+}
+
+// formatTime formats Unix milliseconds, falling back to the current time.
+func formatTime(unixMs int64) string {
+	t := time.Now()
+	if unixMs != 0 {
+		t = time.UnixMilli(unixMs)
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+// formatExpire formats an expiry in Unix milliseconds; zero means no expiry.
+func formatExpire(unixMs int64) string {
+	if unixMs == 0 {
+		return "—"
+	}
+	return time.UnixMilli(unixMs).Format("2006-01-02 15:04")
+}
@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/auth"
+	"github.com/archnets/telegram-bot/internal/logger"
+)
+
+// Category is a kind of bot-initiated message users can turn off.
+type Category int
+
+// Notification categories.
+const (
+	CategoryOrders Category = iota
+	CategoryExpiry
+	CategoryTraffic
+	CategoryAnnouncements
+)
+
+// prefsTTL is how long fetched preferences are reused.
+const prefsTTL = 10 * time.Minute
+
+// Preferences answers whether a user wants a category of messages,
+// based on their notification settings on the backend.
+type Preferences struct {
+	api      *api.Client
+	sessions auth.SessionStore
+
+	mu    sync.Mutex
+	cache map[int64]cachedSettings
+}
+
+type cachedSettings struct {
+	settings  api.NotifySettings
+	fetchedAt time.Time
+}
+
+// NewPreferences creates a preference checker.
+func NewPreferences(client *api.Client, sessions auth.SessionStore) *Preferences {
+	return &Preferences{
+		api:      client,
+		sessions: sessions,
+		cache:    make(map[int64]cachedSettings),
+	}
+}
+
+// Allows returns true if the user wants messages of the category.
+// If the settings can't be fetched (no session, backend error) messages are allowed.
+func (p *Preferences) Allows(ctx context.Context, telegramID int64, c Category) bool {
+	settings, ok := p.get(ctx, telegramID)
+	if !ok {
+		return true
+	}
+
+	switch c {
+	case CategoryOrders:
+		return settings.OrderUpdates
+	case CategoryExpiry:
+		return settings.ExpiryReminders
+	case CategoryTraffic:
+		return settings.TrafficAlerts
+	case CategoryAnnouncements:
+		return settings.Announcements
+	}
+	return true
+}
+
+// Remember stores settings the user just saved, so they apply immediately.
+func (p *Preferences) Remember(telegramID int64, settings api.NotifySettings) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cache[telegramID] = cachedSettings{settings: settings, fetchedAt: time.Now()}
+}
+
+func (p *Preferences) get(ctx context.Context, telegramID int64) (api.NotifySettings, bool) {
+	p.mu.Lock()
+	cached, ok := p.cache[telegramID]
+	p.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < prefsTTL {
+		return cached.settings, true
+	}
+
+	token := p.sessions.GetToken(telegramID)
+	if token == "" {
+		return api.NotifySettings{}, false
+	}
+
+	settings, err := p.api.GetNotifySettings(ctx, token)
+	if err != nil {
+		logger.ForUser(telegramID).Debugf("Notify settings unavailable: %v", err)
+		return api.NotifySettings{}, false
+	}
+
+	p.Remember(telegramID, *settings)
+	return *settings, true
+}
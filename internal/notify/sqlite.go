@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SQLiteStore records processed events so retried deliveries are ignored.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore creates a new SQLite event store.
+// The db connection should already have migrations applied.
+func NewSQLiteStore(db *sql.DB) *SQLiteStore {
+	return &SQLiteStore{db: db}
+}
+
+// Claim marks an event as processed.
+// Returns false if the event was already processed.
+func (s *SQLiteStore) Claim(key string) (bool, error) {
+	res, err := s.db.Exec(`
+		INSERT OR IGNORE INTO processed_events (event_key, processed_at)
+		VALUES (?, ?)
+	`, key, time.Now().Unix())
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// Release removes a claim so the event can be processed again.
+func (s *SQLiteStore) Release(key string) error {
+	_, err := s.db.Exec(`DELETE FROM processed_events WHERE event_key = ?`, key)
+	return err
+}
@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strconv"
+
+	"github.com/archnets/telegram-bot/internal/logger"
+)
+
+// EventTicketReply is posted by the backend when support replies to a ticket.
+const EventTicketReply = "ticket.reply"
+
+// maxReplyPreview is the maximum number of characters of the reply included in the message.
+const maxReplyPreview = 1000
+
+// TicketReplyEvent is the data of a ticket reply event.
+type TicketReplyEvent struct {
+	TicketID   int64  `json:"ticket_id"`
+	FollowID   int64  `json:"follow_id"` // ID of the reply, used for idempotency
+	TelegramID int64  `json:"telegram_id"`
+	Title      string `json:"title"`
+	Content    string `json:"content"`
+}
+
+// RegisterTicketHandlers registers the handler for ticket reply events.
+func RegisterTicketHandlers(r *Router, n *Notifier, store *SQLiteStore) {
+	r.Register(EventTicketReply, func(ctx context.Context, data json.RawMessage) error {
+		var ev TicketReplyEvent
+		if err := json.Unmarshal(data, &ev); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+		}
+		if ev.TicketID == 0 || ev.FollowID == 0 {
+			return fmt.Errorf("%w: missing ticket_id or follow_id", ErrInvalidEvent)
+		}
+		if ev.TelegramID == 0 {
+			return nil
+		}
+
+		key := EventTicketReply + ":" + strconv.FormatInt(ev.FollowID, 10)
+		claimed, err := store.Claim(key)
+		if err != nil {
+			return fmt.Errorf("claim event: %w", err)
+		}
+		if !claimed {
+			logger.Infof("Duplicate %s event for reply %d ignored", EventTicketReply, ev.FollowID)
+			return nil
+		}
+
+		err = n.SendToUser(ctx, ev.TelegramID, "ticket_reply_notify", map[string]any{
+			"ID":      ev.TicketID,
+			"Title":   html.EscapeString(ev.Title),
+			"Content": html.EscapeString(truncate(ev.Content, maxReplyPreview)),
+		})
+		if err != nil && !IsBlocked(err) {
+			_ = store.Release(key)
+			return fmt.Errorf("notify user: %w", err)
+		}
+
+		logger.ForUser(ev.TelegramID).Infof("Ticket %d reply delivered", ev.TicketID)
+		return nil
+	})
+}
+
+// truncate shortens s to at most n characters, adding an ellipsis if cut.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "…"
+}
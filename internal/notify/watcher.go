@@ -0,0 +1,161 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"html"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/logger"
+)
+
+const (
+	// watchInterval is how often a watched order's status is checked.
+	watchInterval = 5 * time.Second
+
+	// watchTimeout is how long an unpaid order is watched.
+	watchTimeout = 30 * time.Minute
+
+	// watchQueueSize is the number of watch requests buffered before Run starts.
+	watchQueueSize = 100
+)
+
+// orderEvents maps order types to the event type announcing their completion.
+var orderEvents = map[int]string{
+	api.OrderTypeSubscribe: EventOrderPurchase,
+	api.OrderTypeRenewal:   EventOrderRenewal,
+	api.OrderTypeRecharge:  EventOrderRecharge,
+	api.OrderTypeReset:     EventOrderReset,
+}
+
+// WatchRequest describes an order placed in the bot.
+type WatchRequest struct {
+	TelegramID    int64
+	Token         string
+	OrderNo       string
+	SubscribeID   int64  // Plan of the order, 0 for recharges
+	SubscribeName string // Shown in the confirmation
+	PaymentMethod string // Shown in the confirmation
+}
+
+// OrderWatcher polls orders placed in the bot until they complete and
+// confirms them with the same messages as the backend's order events.
+// Orders are announced once, whichever path sees the completion first.
+type OrderWatcher struct {
+	api      *api.Client
+	store    *SQLiteStore
+	requests chan WatchRequest
+}
+
+// NewOrderWatcher creates an order watcher.
+func NewOrderWatcher(client *api.Client, store *SQLiteStore) *OrderWatcher {
+	return &OrderWatcher{
+		api:      client,
+		store:    store,
+		requests: make(chan WatchRequest, watchQueueSize),
+	}
+}
+
+// Watch starts watching an order. It never blocks; if the queue is full the
+// order is only confirmed by backend events.
+func (w *OrderWatcher) Watch(req WatchRequest) {
+	select {
+	case w.requests <- req:
+	default:
+		logger.ForUser(req.TelegramID).Warnf("Order watch queue full, order %s not watched", req.OrderNo)
+	}
+}
+
+// Run watches requested orders until ctx is cancelled,
+// delivering confirmations through notifier.
+func (w *OrderWatcher) Run(ctx context.Context, notifier *Notifier) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case req := <-w.requests:
+			go w.watch(ctx, notifier, req)
+		}
+	}
+}
+
+// watch polls a single order until it completes, fails or times out.
+func (w *OrderWatcher) watch(ctx context.Context, n *Notifier, req WatchRequest) {
+	lg := logger.ForUser(req.TelegramID)
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	deadline := time.After(watchTimeout)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline:
+			lg.Infof("Order %s still unpaid, stopped watching", req.OrderNo)
+			return
+		case <-ticker.C:
+		}
+
+		order, err := w.api.GetOrder(ctx, req.Token, req.OrderNo)
+		var apiErr *api.Error
+		if errors.As(err, &apiErr) && api.IsAuthError(apiErr.Code) {
+			lg.Infof("Order %s: session expired, stopped watching", req.OrderNo)
+			return
+		}
+		if err != nil {
+			lg.Debugf("Order %s status check failed: %v", req.OrderNo, err)
+			continue
+		}
+
+		switch order.Status {
+		case api.OrderStatusFinished:
+			if err := w.confirm(ctx, n, req, order); err != nil {
+				lg.Errorf("Order %s confirmation failed: %v", req.OrderNo, err)
+			}
+			return
+		case api.OrderStatusClose, api.OrderStatusFailed:
+			if n.Allows(ctx, req.TelegramID, CategoryOrders) {
+				if err := n.SendToUser(ctx, req.TelegramID, "order_failed", map[string]any{
+					"OrderNo": html.EscapeString(req.OrderNo),
+				}); err != nil {
+					lg.Warnf("Order %s failure notice failed: %v", req.OrderNo, err)
+				}
+			}
+			lg.Infof("Order %s not completed (status %d)", req.OrderNo, order.Status)
+			return
+		}
+	}
+}
+
+// confirm announces a finished order.
+func (w *OrderWatcher) confirm(ctx context.Context, n *Notifier, req WatchRequest, order *api.Order) error {
+	eventType, ok := orderEvents[order.Type]
+	if !ok {
+		eventType = EventOrderPurchase
+	}
+
+	ev := &OrderEvent{
+		OrderNo:       order.OrderNo,
+		TelegramID:    req.TelegramID,
+		SubscribeName: req.SubscribeName,
+		Amount:        order.Amount,
+		PaymentMethod: req.PaymentMethod,
+		PaidAt:        time.Now().UnixMilli(),
+	}
+
+	// Best effort: the confirmation is still sent without these details
+	if eventType == EventOrderRecharge {
+		if info, err := w.api.GetUserInfo(ctx, req.Token); err == nil {
+			ev.Balance = info.Balance
+		}
+	} else if subs, err := w.api.GetUserSubscriptions(ctx, req.Token); err == nil {
+		for _, sub := range subs {
+			if sub.SubscribeID == req.SubscribeID && sub.ExpireTime > ev.ExpireTime {
+				ev.ExpireTime = sub.ExpireTime
+			}
+		}
+	}
+
+	return deliverOrder(ctx, n, w.store, eventType, ev)
+}
@@ -0,0 +1,239 @@
+// Package reminder periodically checks users' subscriptions and sends
+// expiry and traffic-quota reminders, each at most once.
+package reminder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/auth"
+	"github.com/archnets/telegram-bot/internal/core"
+	"github.com/archnets/telegram-bot/internal/i18n"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/archnets/telegram-bot/internal/notify"
+)
+
+// userDelay spaces out backend calls while walking users.
+const userDelay = 100 * time.Millisecond
+
+// Config holds reminder options.
+type Config struct {
+	Interval        time.Duration // How often users are checked
+	ExpiryDays      []int         // Days before expiry to remind, e.g. [3, 1]
+	TrafficPercents []int         // Usage percentages to remind at, e.g. [80, 95, 100]
+}
+
+// Scheduler sends subscription reminders.
+type Scheduler struct {
+	store    *SQLiteStore
+	sessions auth.SessionStore
+	api      *api.Client
+	notifier *notify.Notifier
+	cfg      Config
+}
+
+// NewScheduler creates a new reminder scheduler.
+func NewScheduler(store *SQLiteStore, sessions auth.SessionStore, client *api.Client, cfg Config) *Scheduler {
+	sort.Ints(cfg.ExpiryDays)
+	sort.Ints(cfg.TrafficPercents)
+	return &Scheduler{
+		store:    store,
+		sessions: sessions,
+		api:      client,
+		cfg:      cfg,
+	}
+}
+
+// Run checks all users periodically until ctx is cancelled,
+// delivering reminders through notifier.
+func (s *Scheduler) Run(ctx context.Context, notifier *notify.Notifier) {
+	s.notifier = notifier
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context) {
+	users, err := s.sessions.Active()
+	if err != nil {
+		logger.Errorf("Reminder: list users failed: %v", err)
+		return
+	}
+
+	for _, u := range users {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.checkUser(ctx, u); err != nil {
+			logger.ForUser(u.TelegramID).Warnf("Reminder check failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(userDelay):
+		}
+	}
+}
+
+func (s *Scheduler) checkUser(ctx context.Context, u auth.ActiveSession) error {
+	subs, err := s.api.GetUserSubscriptions(ctx, u.Token)
+
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) && api.IsAuthError(apiErr.Code) {
+		return nil // Session expired; checked again after the user's next login
+	}
+	if err != nil {
+		return err
+	}
+	// Respect the user's notification settings
+	expiry := s.notifier.Allows(ctx, u.TelegramID, notify.CategoryExpiry)
+	traffic := s.notifier.Allows(ctx, u.TelegramID, notify.CategoryTraffic)
+
+	now := time.Now()
+	for _, sub := range subs {
+		if !core.IsSubscriptionActive(sub, now) {
+			continue
+		}
+		if expiry {
+			if err := s.checkExpiry(ctx, u.TelegramID, sub, now); err != nil {
+				return err
+			}
+		}
+		if traffic {
+			if err := s.checkTraffic(ctx, u.TelegramID, sub); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkExpiry sends the reminder for the closest configured day threshold reached.
+// Keys include the expiry time so renewed subscriptions are reminded again.
+func (s *Scheduler) checkExpiry(ctx context.Context, telegramID int64, sub api.UserSubscription, now time.Time) error {
+	if sub.ExpireTime == 0 || len(s.cfg.ExpiryDays) == 0 {
+		return nil
+	}
+
+	expiry := time.UnixMilli(sub.ExpireTime)
+	daysLeft := int(math.Ceil(expiry.Sub(now).Hours() / 24))
+
+	// Smallest threshold reached; larger ones are marked sent along with it
+	reached := -1
+	for i, days := range s.cfg.ExpiryDays {
+		if daysLeft <= days {
+			reached = i
+			break
+		}
+	}
+	if reached < 0 {
+		return nil
+	}
+
+	data := map[string]any{
+		"Name":       html.EscapeString(subscriptionName(sub)),
+		"Days":       daysLeft,
+		"ExpireDate": expiry.Format("2006-01-02"),
+	}
+	return s.remindOnce(ctx, telegramID, sub.ID, expiryKeys(s.cfg.ExpiryDays[reached:], sub.ExpireTime), "reminder_expiry", data)
+}
+
+// checkTraffic sends the reminder for the highest usage threshold reached.
+// Thresholds no longer reached (after a traffic reset) are re-armed.
+func (s *Scheduler) checkTraffic(ctx context.Context, telegramID int64, sub api.UserSubscription) error {
+	if sub.Traffic <= 0 || len(s.cfg.TrafficPercents) == 0 {
+		return nil // Unlimited traffic
+	}
+
+	used := sub.Download + sub.Upload
+	percent := float64(used) / float64(sub.Traffic) * 100
+
+	var reached []string
+	for _, p := range s.cfg.TrafficPercents {
+		key := fmt.Sprintf("traffic:%d", p)
+		if percent >= float64(p) {
+			reached = append(reached, key)
+			continue
+		}
+		if err := s.store.release(sub.ID, key); err != nil {
+			return err
+		}
+	}
+	if len(reached) == 0 {
+		return nil
+	}
+
+	// Highest threshold first so it decides whether to send
+	for i, j := 0, len(reached)-1; i < j; i, j = i+1, j-1 {
+		reached[i], reached[j] = reached[j], reached[i]
+	}
+
+	messageID := "reminder_traffic"
+	if percent >= 100 {
+		messageID = "reminder_traffic_exhausted"
+	}
+	data := map[string]any{
+		"Name":    html.EscapeString(subscriptionName(sub)),
+		"Percent": int(percent),
+		"Used":    i18n.FormatBytes(used),
+		"Total":   i18n.FormatBytes(sub.Traffic),
+	}
+	return s.remindOnce(ctx, telegramID, sub.ID, reached, messageID, data)
+}
+
+// remindOnce claims all keys and sends the message if the first key was not sent before.
+func (s *Scheduler) remindOnce(ctx context.Context, telegramID, subID int64, keys []string, messageID string, data map[string]any) error {
+	first, err := s.store.claim(subID, keys[0])
+	if err != nil {
+		return err
+	}
+	for _, key := range keys[1:] {
+		if _, err := s.store.claim(subID, key); err != nil {
+			return err
+		}
+	}
+	if !first {
+		return nil
+	}
+
+	err = s.notifier.SendToUser(ctx, telegramID, messageID, data)
+	if err != nil && !notify.IsBlocked(err) {
+		_ = s.store.release(subID, keys[0])
+		return fmt.Errorf("send %s: %w", messageID, err)
+	}
+
+	logger.ForUser(telegramID).Infof("Reminder %s sent for subscription %d", keys[0], subID)
+	return nil
+}
+
+func expiryKeys(days []int, expireTime int64) []string {
+	keys := make([]string, len(days))
+	for i, d := range days {
+		keys[i] = fmt.Sprintf("expire:%d:%d", d, expireTime)
+	}
+	return keys
+}
+
+func subscriptionName(sub api.UserSubscription) string {
+	if sub.CustomName != "" {
+		return sub.CustomName
+	}
+	return sub.Subscribe.Name
+}
@@ -0,0 +1,38 @@
+package reminder
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SQLiteStore tracks sent reminders.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore creates a new SQLite reminder store.
+// The db connection should already have migrations applied.
+func NewSQLiteStore(db *sql.DB) *SQLiteStore {
+	return &SQLiteStore{db: db}
+}
+
+// claim marks a reminder as sent. Returns false if it was already sent.
+func (s *SQLiteStore) claim(subID int64, reminder string) (bool, error) {
+	res, err := s.db.Exec(`
+		INSERT OR IGNORE INTO reminders_sent (user_subscribe_id, reminder, sent_at)
+		VALUES (?, ?, ?)
+	`, subID, reminder, time.Now().Unix())
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// release forgets a sent reminder so it can fire again.
+func (s *SQLiteStore) release(subID int64, reminder string) error {
+	_, err := s.db.Exec(`
+		DELETE FROM reminders_sent WHERE user_subscribe_id = ? AND reminder = ?
+	`, subID, reminder)
+	return err
+}
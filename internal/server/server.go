@@ -0,0 +1,75 @@
+// Package server provides the built-in HTTP server used for webhook delivery
+// and backend-facing endpoints.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/logger"
+)
+
+// shutdownTimeout bounds how long in-flight requests may take on shutdown.
+const shutdownTimeout = 10 * time.Second
+
+// Server is a small wrapper around http.Server with graceful shutdown.
+type Server struct {
+	http     *http.Server
+	mux      *http.ServeMux
+	handlers int
+}
+
+// New creates a new server listening on addr.
+func New(addr string) *Server {
+	mux := http.NewServeMux()
+	return &Server{
+		http: &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+		mux: mux,
+	}
+}
+
+// Handle registers a handler for the given pattern.
+func (s *Server) Handle(pattern string, h http.Handler) {
+	s.mux.Handle(pattern, h)
+	s.handlers++
+	logger.Debugf("HTTP route registered: %s", pattern)
+}
+
+// HasHandlers returns true if any route has been registered.
+func (s *Server) HasHandlers() bool {
+	return s.handlers > 0
+}
+
+// Run serves HTTP until ctx is cancelled, then shuts down gracefully.
+func (s *Server) Run(ctx context.Context) error {
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Infof("HTTP server listening on %s", s.http.Addr)
+		errCh <- s.http.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("listen: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := s.http.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	logger.Infof("HTTP server stopped")
+	return nil
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers used to sign backend-to-bot requests.
+const (
+	HeaderTimestamp = "X-Archnet-Timestamp"
+	HeaderSignature = "X-Archnet-Signature"
+)
+
+const (
+	// maxSignatureSkew is how far a request timestamp may drift from now.
+	maxSignatureSkew = 5 * time.Minute
+
+	// maxBodySize limits signed request bodies.
+	maxBodySize = 1 << 20
+)
+
+// Sign computes the signature for a request body.
+// Signature = hex(HMAC-SHA256("<timestamp>.<body>", secret))
+func Sign(secret string, timestamp int64, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	h.Write([]byte("."))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// VerifySignature checks a request signature and timestamp freshness.
+func VerifySignature(secret, timestamp, signature string, body []byte, now time.Time) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp")
+	}
+
+	skew := now.Sub(time.Unix(ts, 0))
+	if skew > maxSignatureSkew || skew < -maxSignatureSkew {
+		return fmt.Errorf("timestamp outside allowed window")
+	}
+
+	expected := Sign(secret, ts, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// RequireSignature rejects requests that are not signed with the shared secret.
+// The body is restored so next can read it.
+func RequireSignature(secret string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+		if err != nil {
+			http.Error(w, "read body", http.StatusBadRequest)
+			return
+		}
+
+		err = VerifySignature(secret, r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderSignature), body, time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,95 @@
+package stars
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Payment is a Telegram Stars payment received for a backend order.
+type Payment struct {
+	ChargeID   string // Telegram payment charge ID
+	TelegramID int64
+	OrderNo    string
+	Stars      int
+	Finalized  bool // Order was marked paid on the backend
+	Refunded   bool
+	Held       bool // Order was closed or failed; left to an admin
+}
+
+// SQLiteStore records received Stars payments until they are finalized or refunded.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore creates a new SQLite payment store.
+// The db connection should already have migrations applied.
+func NewSQLiteStore(db *sql.DB) *SQLiteStore {
+	return &SQLiteStore{db: db}
+}
+
+// record saves a payment. Recording the same charge twice is a no-op.
+func (s *SQLiteStore) record(p Payment) error {
+	_, err := s.db.Exec(`
+		INSERT OR IGNORE INTO star_payments (charge_id, telegram_id, order_no, stars, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, p.ChargeID, p.TelegramID, p.OrderNo, p.Stars, time.Now().Unix())
+	return err
+}
+
+// get returns a payment by charge ID, or nil if there is none.
+func (s *SQLiteStore) get(chargeID string) (*Payment, error) {
+	var p Payment
+	err := s.db.QueryRow(`
+		SELECT charge_id, telegram_id, order_no, stars, finalized, refunded, held
+		FROM star_payments WHERE charge_id = ?
+	`, chargeID).Scan(&p.ChargeID, &p.TelegramID, &p.OrderNo, &p.Stars, &p.Finalized, &p.Refunded, &p.Held)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// pending returns payments not yet finalized on the backend, nor held.
+func (s *SQLiteStore) pending() ([]Payment, error) {
+	rows, err := s.db.Query(`
+		SELECT charge_id, telegram_id, order_no, stars
+		FROM star_payments WHERE finalized = 0 AND refunded = 0 AND held = 0
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []Payment
+	for rows.Next() {
+		var p Payment
+		if err := rows.Scan(&p.ChargeID, &p.TelegramID, &p.OrderNo, &p.Stars); err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+// markFinalized records that the payment's order was marked paid.
+func (s *SQLiteStore) markFinalized(chargeID string) error {
+	_, err := s.db.Exec(`UPDATE star_payments SET finalized = 1 WHERE charge_id = ?`, chargeID)
+	return err
+}
+
+// markRefunded records that the payment was refunded.
+func (s *SQLiteStore) markRefunded(chargeID string) error {
+	_, err := s.db.Exec(`UPDATE star_payments SET refunded = 1 WHERE charge_id = ?`, chargeID)
+	return err
+}
+
+// markHeld records that the payment's order can't be finalized automatically.
+func (s *SQLiteStore) markHeld(chargeID string) error {
+	_, err := s.db.Exec(`UPDATE star_payments SET held = 1 WHERE charge_id = ?`, chargeID)
+	return err
+}
@@ -0,0 +1,302 @@
+// Package stars sells backend orders for Telegram Stars through in-chat invoices.
+package stars
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"sync"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/auth"
+	"github.com/archnets/telegram-bot/internal/logger"
+	"github.com/archnets/telegram-bot/internal/notify"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Currency is the invoice currency of Telegram Stars.
+const Currency = "XTR"
+
+// retryInterval is how often payments not yet finalized are retried.
+const retryInterval = time.Minute
+
+var (
+	// ErrNotPayable means the order is no longer awaiting payment.
+	ErrNotPayable = errors.New("order is not awaiting payment")
+	// ErrAmountMismatch means the invoice no longer matches the order price.
+	ErrAmountMismatch = errors.New("invoice does not match the order")
+	// ErrPaymentNotFound means no payment was received with the charge ID.
+	ErrPaymentNotFound = errors.New("payment not found")
+	// ErrAlreadyRefunded means the payment was already refunded.
+	ErrAlreadyRefunded = errors.New("payment already refunded")
+	// ErrOrderFulfilled means the order of a payment is paid or finished on the
+	// backend and has to be reverted in the panel before a refund.
+	ErrOrderFulfilled = errors.New("order already fulfilled")
+	// ErrNotRecorded means a received payment could not be saved, so nothing
+	// will finalize it: admins have to settle it by hand.
+	ErrNotRecorded = errors.New("payment not recorded")
+	// ErrPaymentHeld means the order of a payment was closed or failed before it
+	// could be marked paid. The payment is held until an admin refunds it or
+	// fulfills the order in the panel.
+	ErrPaymentHeld = errors.New("order of the payment is no longer pending")
+)
+
+// Invoice describes an order to be paid with Stars.
+type Invoice struct {
+	ChatID      int64
+	OrderNo     string
+	Title       string // 1-32 characters
+	Description string // 1-255 characters
+}
+
+// Provider issues Stars invoices for backend orders and finalizes them once paid.
+type Provider struct {
+	store  *SQLiteStore
+	api    *api.Client
+	admin  *api.AdminSession
+	tokens *auth.TokenSource
+	rate   int // Stars per 1.00 of the order currency
+
+	mu sync.Mutex // Serializes finalizing and refunding
+}
+
+// NewProvider creates a new Stars payment provider.
+// Paid orders are marked paid on the backend with the admin session; they are
+// looked up with the paying user's token, logging them in again if needed.
+func NewProvider(store *SQLiteStore, client *api.Client, admin *api.AdminSession, tokens *auth.TokenSource, rate int) *Provider {
+	return &Provider{
+		store:  store,
+		api:    client,
+		admin:  admin,
+		tokens: tokens,
+		rate:   rate,
+	}
+}
+
+// Enabled reports whether Stars payments are accepted.
+// Finalizing orders requires admin credentials.
+func (p *Provider) Enabled() bool {
+	return p != nil && p.rate > 0 && p.admin.Enabled()
+}
+
+// Price converts an order amount (cents) to Stars, rounding up.
+func (p *Provider) Price(amount int64) int {
+	return int((amount*int64(p.rate) + 99) / 100)
+}
+
+// SendInvoice sends an invoice for an order, priced from the backend amount.
+// The order number is the invoice payload.
+func (p *Provider) SendInvoice(ctx context.Context, b *bot.Bot, token string, inv Invoice) error {
+	order, err := p.api.GetOrder(ctx, token, inv.OrderNo)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.SendInvoice(ctx, &bot.SendInvoiceParams{
+		ChatID:      inv.ChatID,
+		Title:       inv.Title,
+		Description: inv.Description,
+		Payload:     inv.OrderNo,
+		Currency:    Currency,
+		Prices: []models.LabeledPrice{
+			{Label: inv.Title, Amount: p.Price(order.Amount)},
+		},
+	})
+	return err
+}
+
+// Validate checks a pre-checkout query against its order on the backend.
+func (p *Provider) Validate(ctx context.Context, token string, q *models.PreCheckoutQuery) error {
+	if q.Currency != Currency {
+		return ErrAmountMismatch
+	}
+
+	order, err := p.api.GetOrder(ctx, token, q.InvoicePayload)
+	if err != nil {
+		return err
+	}
+	if order.Status != api.OrderStatusPending {
+		return ErrNotPayable
+	}
+	if p.Price(order.Amount) != q.TotalAmount {
+		return ErrAmountMismatch
+	}
+	return nil
+}
+
+// Complete records a successful payment and marks its order paid on the backend.
+// The payment is kept if finalizing fails, and Run retries it. ErrNotRecorded
+// and ErrPaymentHeld mean admins must settle it.
+func (p *Provider) Complete(ctx context.Context, telegramID int64, sp *models.SuccessfulPayment) error {
+	payment := Payment{
+		ChargeID:   sp.TelegramPaymentChargeID,
+		TelegramID: telegramID,
+		OrderNo:    sp.InvoicePayload,
+		Stars:      sp.TotalAmount,
+	}
+	if err := p.store.record(payment); err != nil {
+		return fmt.Errorf("%w: %v", ErrNotRecorded, err)
+	}
+	return p.finalize(ctx, payment)
+}
+
+// Refund returns the Stars of a payment to the user and reverts its order.
+// The payment is marked refunded before its order is closed, so it is never
+// finalized afterwards. Paid or finished orders can't be undone from here:
+// they are refused with ErrOrderFulfilled until they are closed in the panel.
+func (p *Provider) Refund(ctx context.Context, b *bot.Bot, chargeID string) (*Payment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, err := p.store.get(chargeID)
+	if err != nil {
+		return nil, err
+	}
+	if payment == nil {
+		return nil, ErrPaymentNotFound
+	}
+	if payment.Refunded {
+		return payment, ErrAlreadyRefunded
+	}
+
+	order, err := p.order(ctx, *payment)
+	if err != nil {
+		return payment, err
+	}
+	if order.Status == api.OrderStatusPaid || order.Status == api.OrderStatusFinished {
+		return payment, ErrOrderFulfilled
+	}
+
+	if _, err := b.RefundStarPayment(ctx, &bot.RefundStarPaymentParams{
+		UserID:                  payment.TelegramID,
+		TelegramPaymentChargeID: payment.ChargeID,
+	}); err != nil {
+		return payment, err
+	}
+
+	payment.Refunded = true
+	if err := p.store.markRefunded(chargeID); err != nil {
+		return payment, fmt.Errorf("mark refunded: %w", err)
+	}
+
+	// An open order could otherwise still be paid another way
+	if order.Status == api.OrderStatusPending {
+		if err := p.setOrderStatus(ctx, order.ID, api.OrderStatusClose, ""); err != nil {
+			logger.Warnf("Stars payment %s refunded but order %s not closed: %v", chargeID, payment.OrderNo, err)
+		}
+	}
+	return payment, nil
+}
+
+// Run retries finalizing received payments until ctx is cancelled.
+// Admins are told through notifier about payments that get held.
+func (p *Provider) Run(ctx context.Context, notifier *notify.Notifier) {
+	ticker := time.NewTicker(retryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		payments, err := p.store.pending()
+		if err != nil {
+			logger.Errorf("Failed to load pending Stars payments: %v", err)
+			continue
+		}
+		for _, payment := range payments {
+			err := p.finalize(ctx, payment)
+			if errors.Is(err, ErrPaymentHeld) {
+				notifier.SendToAdmins(ctx, "admin_stars_payment_held", AlertData(payment))
+			}
+			if err != nil {
+				logger.Warnf("Stars payment %s (order %s) not finalized: %v", payment.ChargeID, payment.OrderNo, err)
+			}
+		}
+	}
+}
+
+// finalize marks the payment's pending order paid, unless the backend already
+// did. An order closed or failed in the meantime is not forced to paid: the
+// payment is held and ErrPaymentHeld returned.
+func (p *Provider) finalize(ctx context.Context, payment Payment) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// The payment may have been refunded since it was loaded
+	current, err := p.store.get(payment.ChargeID)
+	if err != nil {
+		return err
+	}
+	if current == nil || current.Refunded || current.Finalized || current.Held {
+		return nil
+	}
+
+	order, err := p.order(ctx, payment)
+	if err != nil {
+		return err
+	}
+
+	switch order.Status {
+	case api.OrderStatusPaid, api.OrderStatusFinished:
+	case api.OrderStatusPending:
+		if err := p.setOrderStatus(ctx, order.ID, api.OrderStatusPaid, payment.ChargeID); err != nil {
+			return fmt.Errorf("mark order %s paid: %w", payment.OrderNo, err)
+		}
+	default:
+		if err := p.store.markHeld(payment.ChargeID); err != nil {
+			return fmt.Errorf("mark held: %w", err)
+		}
+		return fmt.Errorf("%w: order %s has status %d", ErrPaymentHeld, payment.OrderNo, order.Status)
+	}
+
+	if err := p.store.markFinalized(payment.ChargeID); err != nil {
+		return fmt.Errorf("mark finalized: %w", err)
+	}
+	logger.Infof("Stars payment %s finalized order %s", payment.ChargeID, payment.OrderNo)
+	return nil
+}
+
+// order fetches the order of a payment on behalf of the paying user.
+// Their session is renewed if it expired, so finalizing doesn't wait for
+// the user to come back.
+func (p *Provider) order(ctx context.Context, payment Payment) (*api.Order, error) {
+	ctx = auth.WithUser(ctx, auth.TelegramUser{ID: payment.TelegramID})
+
+	token, err := p.tokens.Token(ctx, payment.TelegramID)
+	if err != nil {
+		return nil, fmt.Errorf("session for user %d: %w", payment.TelegramID, err)
+	}
+
+	order, err := p.api.GetOrder(ctx, token, payment.OrderNo)
+	if err != nil {
+		return nil, fmt.Errorf("fetch order %s: %w", payment.OrderNo, err)
+	}
+	return order, nil
+}
+
+// setOrderStatus changes the status of an order with the admin session.
+func (p *Provider) setOrderStatus(ctx context.Context, orderID int64, status int, tradeNo string) error {
+	return p.admin.Do(ctx, func(adminToken string) error {
+		return p.api.UpdateOrderStatus(ctx, adminToken, api.OrderStatusUpdate{
+			ID:      orderID,
+			Status:  status,
+			TradeNo: tradeNo,
+		})
+	})
+}
+
+// AlertData returns the template data of admin alerts about a payment.
+func AlertData(payment Payment) map[string]any {
+	return map[string]any{
+		"ChargeID": html.EscapeString(payment.ChargeID),
+		"OrderNo":  html.EscapeString(payment.OrderNo),
+		"UserID":   payment.TelegramID,
+		"Stars":    payment.Stars,
+	}
+}
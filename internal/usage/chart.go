@@ -0,0 +1,88 @@
+package usage
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+)
+
+// Chart dimensions in pixels.
+const (
+	chartWidth  = 960
+	chartHeight = 480
+	chartMargin = 32
+	gridLines   = 4
+)
+
+// Chart colors.
+var (
+	colorBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	colorGrid       = color.RGBA{0xe5, 0xe7, 0xeb, 0xff}
+	colorAxis       = color.RGBA{0x9c, 0xa3, 0xaf, 0xff}
+	colorDownload   = color.RGBA{0x3b, 0x82, 0xf6, 0xff}
+	colorUpload     = color.RGBA{0x10, 0xb9, 0x81, 0xff}
+	colorToday      = color.RGBA{0xf3, 0xf4, 0xf6, 0xff}
+)
+
+// Chart renders days as a PNG bar chart, one bar per day with download
+// stacked under upload. The last day (today) is highlighted. Values are
+// scaled to the busiest day; labels are left to the message caption.
+func Chart(days []Day) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	fill(img, img.Bounds(), colorBackground)
+
+	plot := image.Rect(chartMargin, chartMargin, chartWidth-chartMargin, chartHeight-chartMargin)
+
+	var peak int64
+	for _, d := range days {
+		peak = max(peak, d.Total())
+	}
+
+	slot := plot.Dx() / max(len(days), 1)
+	barWidth := max(slot*7/10, 2)
+
+	if len(days) > 0 {
+		x := plot.Min.X + (len(days)-1)*slot
+		fill(img, image.Rect(x, plot.Min.Y, x+slot, plot.Max.Y), colorToday)
+	}
+
+	for i := 1; i <= gridLines; i++ {
+		y := plot.Max.Y - plot.Dy()*i/gridLines
+		fill(img, image.Rect(plot.Min.X, y, plot.Max.X, y+1), colorGrid)
+	}
+
+	for i, d := range days {
+		if peak == 0 {
+			break
+		}
+		x := plot.Min.X + i*slot + (slot-barWidth)/2
+		down := scale(d.Download, peak, plot.Dy())
+		up := scale(d.Total(), peak, plot.Dy()) - down
+
+		fill(img, image.Rect(x, plot.Max.Y-down, x+barWidth, plot.Max.Y), colorDownload)
+		fill(img, image.Rect(x, plot.Max.Y-down-up, x+barWidth, plot.Max.Y-down), colorUpload)
+	}
+
+	fill(img, image.Rect(plot.Min.X, plot.Max.Y, plot.Max.X, plot.Max.Y+2), colorAxis)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// scale converts a value to a bar height in pixels, keeping non-zero values visible.
+func scale(v, peak int64, height int) int {
+	h := int(v * int64(height) / peak)
+	if v > 0 && h == 0 {
+		h = 1
+	}
+	return h
+}
+
+func fill(img draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, &image.Uniform{C: c}, image.Point{}, draw.Src)
+}
@@ -0,0 +1,71 @@
+package usage
+
+import (
+	"database/sql"
+	"time"
+)
+
+// sample is a subscription's cumulative traffic counters at a point in time.
+type sample struct {
+	Download  int64
+	Upload    int64
+	SampledAt time.Time
+}
+
+// SQLiteStore persists traffic samples.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore creates a new SQLite usage store.
+// The db connection should already have migrations applied.
+func NewSQLiteStore(db *sql.DB) *SQLiteStore {
+	return &SQLiteStore{db: db}
+}
+
+// record stores a sample of a subscription's counters.
+func (s *SQLiteStore) record(subID, telegramID int64, smp sample) error {
+	_, err := s.db.Exec(`
+		INSERT OR REPLACE INTO usage_samples (user_subscribe_id, telegram_id, download, upload, sampled_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, subID, telegramID, smp.Download, smp.Upload, smp.SampledAt.Unix())
+	return err
+}
+
+// samples returns a user's samples of a subscription taken since the given time,
+// preceded by the last earlier sample (the baseline), oldest first.
+func (s *SQLiteStore) samples(subID, telegramID int64, since time.Time) ([]sample, error) {
+	rows, err := s.db.Query(`
+		SELECT download, upload, sampled_at FROM (
+			SELECT download, upload, sampled_at FROM usage_samples
+			WHERE user_subscribe_id = ? AND telegram_id = ? AND sampled_at < ?
+			ORDER BY sampled_at DESC LIMIT 1
+		)
+		UNION ALL
+		SELECT download, upload, sampled_at FROM usage_samples
+		WHERE user_subscribe_id = ? AND telegram_id = ? AND sampled_at >= ?
+		ORDER BY sampled_at
+	`, subID, telegramID, since.Unix(), subID, telegramID, since.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []sample
+	for rows.Next() {
+		var smp sample
+		var sampledAt int64
+		if err := rows.Scan(&smp.Download, &smp.Upload, &sampledAt); err != nil {
+			return nil, err
+		}
+		smp.SampledAt = time.Unix(sampledAt, 0)
+		list = append(list, smp)
+	}
+	return list, rows.Err()
+}
+
+// prune deletes samples taken before the given time.
+func (s *SQLiteStore) prune(before time.Time) error {
+	_, err := s.db.Exec(`DELETE FROM usage_samples WHERE sampled_at < ?`, before.Unix())
+	return err
+}
@@ -0,0 +1,169 @@
+// Package usage periodically samples users' subscription traffic counters
+// and turns the samples into daily usage history.
+package usage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/archnets/telegram-bot/internal/api"
+	"github.com/archnets/telegram-bot/internal/auth"
+	"github.com/archnets/telegram-bot/internal/core"
+	"github.com/archnets/telegram-bot/internal/logger"
+)
+
+// MaxDays is the longest history that can be requested.
+const MaxDays = 30
+
+// retention is how long samples are kept; a little over MaxDays so the
+// oldest day still has a baseline sample before it.
+const retention = (MaxDays + 5) * 24 * time.Hour
+
+// userDelay spaces out backend calls while walking users.
+const userDelay = 100 * time.Millisecond
+
+// Day is the traffic used by a subscription on one calendar day.
+type Day struct {
+	Date     time.Time // Local midnight
+	Download int64
+	Upload   int64
+}
+
+// Total returns the day's download and upload combined.
+func (d Day) Total() int64 {
+	return d.Download + d.Upload
+}
+
+// Sampler records subscription traffic counters and reports daily usage.
+type Sampler struct {
+	store    *SQLiteStore
+	sessions auth.SessionStore
+	api      *api.Client
+	interval time.Duration
+}
+
+// NewSampler creates a new usage sampler.
+func NewSampler(store *SQLiteStore, sessions auth.SessionStore, client *api.Client, interval time.Duration) *Sampler {
+	return &Sampler{
+		store:    store,
+		sessions: sessions,
+		api:      client,
+		interval: interval,
+	}
+}
+
+// Run samples all users periodically until ctx is cancelled.
+func (s *Sampler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Sampler) runOnce(ctx context.Context) {
+	now := time.Now()
+	if err := s.store.prune(now.Add(-retention)); err != nil {
+		logger.Errorf("Usage: prune samples failed: %v", err)
+	}
+
+	users, err := s.sessions.Active()
+	if err != nil {
+		logger.Errorf("Usage: list users failed: %v", err)
+		return
+	}
+
+	for _, u := range users {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.sampleUser(ctx, u, now); err != nil {
+			logger.ForUser(u.TelegramID).Warnf("Usage sample failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(userDelay):
+		}
+	}
+}
+
+func (s *Sampler) sampleUser(ctx context.Context, u auth.ActiveSession, now time.Time) error {
+	subs, err := s.api.GetUserSubscriptions(ctx, u.Token)
+
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) && api.IsAuthError(apiErr.Code) {
+		return nil // Session expired; sampled again after the user's next login
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range subs {
+		if !core.IsSubscriptionActive(sub, now) {
+			continue
+		}
+		smp := sample{Download: sub.Download, Upload: sub.Upload, SampledAt: now}
+		if err := s.store.record(sub.ID, u.TelegramID, smp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Daily returns the traffic a user's subscription used on each of the last
+// days calendar days (today included), oldest first. Returns nil if the
+// subscription has not been sampled yet.
+func (s *Sampler) Daily(telegramID, subID int64, days int, now time.Time) ([]Day, error) {
+	days = min(max(days, 1), MaxDays)
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	start := today.AddDate(0, 0, -(days - 1))
+
+	samples, err := s.store.samples(subID, telegramID, start)
+	if err != nil {
+		return nil, err
+	}
+	if len(samples) == 0 {
+		return nil, nil
+	}
+
+	result := make([]Day, days)
+	for i := range result {
+		result[i].Date = start.AddDate(0, 0, i)
+	}
+
+	for i := 1; i < len(samples); i++ {
+		prev, cur := samples[i-1], samples[i]
+		if cur.SampledAt.Before(start) {
+			continue
+		}
+		t := cur.SampledAt.In(now.Location())
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, now.Location())
+		idx := int(day.Sub(start).Hours()/24 + 0.5)
+		if idx < 0 || idx >= days {
+			continue
+		}
+		result[idx].Download += delta(prev.Download, cur.Download)
+		result[idx].Upload += delta(prev.Upload, cur.Upload)
+	}
+	return result, nil
+}
+
+// delta returns the traffic counted between two cumulative readings.
+// A decrease means the counter was reset, so everything since the reset counts.
+func delta(prev, cur int64) int64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}